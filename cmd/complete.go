@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// runCompleteApp is the entrypoint for `bd-claim complete`.
+func runCompleteApp(args []string) int {
+	cfg, err := parseCompleteFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	result := runComplete(cfg)
+	return outputResult(cfg, result)
+}
+
+func parseCompleteFlags(args []string) (config, error) {
+	cfg := config{command: "complete"}
+	fs := flag.NewFlagSet("bd-claim complete", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	registerCommonFlags(fs, &cfg)
+	fs.StringVar(&cfg.issueID, "issue", "", "ID of the claimed issue to close (required)")
+	fs.StringVar(&cfg.reason, "reason", "", "Why the issue is being closed (recorded as the close reason)")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+func runComplete(cfg config) application.ClaimIssueResult {
+	agent, err := parseAgent(cfg.agent)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	if cfg.issueID == "" {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--issue flag is required")
+	}
+
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	defer repo.Close()
+
+	clock := infrastructure.NewSystemClock()
+	useCase := application.NewCompleteIssueUseCase(repo, clock, logger)
+
+	req := application.CompleteIssueRequest{
+		Agent:   agent,
+		IssueID: domain.IssueId(cfg.issueID),
+		Reason:  cfg.reason,
+	}
+
+	return useCase.Execute(context.Background(), req)
+}
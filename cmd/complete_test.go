@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/application"
+)
+
+func TestParseCompleteFlags(t *testing.T) {
+	cfg, err := parseCompleteFlags([]string{"--agent", "test-agent", "--issue", "bd-1", "--reason", "done", "--human"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "complete" {
+		t.Errorf("expected command 'complete', got '%s'", cfg.command)
+	}
+	if cfg.agent != "test-agent" || cfg.issueID != "bd-1" || cfg.reason != "done" || !cfg.human {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	if _, err := parseCompleteFlags([]string{"--lease", "5m"}); err == nil {
+		t.Error("expected error for claim-only flag")
+	}
+}
+
+func TestRunComplete_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config
+	}{
+		{"missing issue", config{agent: "test-agent"}},
+		{"missing agent", config{issueID: "bd-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runComplete(tt.cfg)
+			if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+				t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+			}
+		})
+	}
+}
+
+func TestRunApp_ClaimThenComplete(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	complete := func(agent string, extra ...string) (int, application.ClaimIssueResult) {
+		buf.Reset()
+		args := append([]string{"complete", "--agent", agent, "--issue", "test-123", "--workspace", workspaceRoot}, extra...)
+		code := runApp(args)
+		var result application.ClaimIssueResult
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v: %s", err, buf.String())
+		}
+		return code, result
+	}
+
+	// Nobody can close an issue that has not been claimed
+	if code, result := complete("test-agent"); code != 1 || result.Error == nil || result.Error.Code != "NOT_CLAIMED" {
+		t.Errorf("expected NOT_CLAIMED error, got %d %+v", code, result.Error)
+	}
+
+	if code := runApp([]string{"--agent", "test-agent", "--workspace", workspaceRoot}); code != 0 {
+		t.Fatalf("claim failed with exit code %d: %s", code, buf.String())
+	}
+
+	// Another agent cannot close it
+	if code, result := complete("other-agent"); code != 1 || result.Error == nil || result.Error.Code != "NOT_OWNER" {
+		t.Errorf("expected NOT_OWNER error, got %d %+v", code, result.Error)
+	}
+
+	code, result := complete("test-agent", "--reason", "shipped")
+	if code != 0 {
+		t.Fatalf("complete failed with exit code %d: %+v", code, result.Error)
+	}
+	if result.Issue == nil || result.Issue.Status != "closed" {
+		t.Errorf("expected closed issue, got %+v", result.Issue)
+	}
+
+	// Closing twice reports that the issue is already closed
+	if code, result := complete("test-agent"); code != 1 || result.Error == nil || result.Error.Code != "ALREADY_CLOSED" {
+		t.Errorf("expected ALREADY_CLOSED error, got %d %+v", code, result.Error)
+	}
+}
+
+func TestRunApp_CompleteHuman(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	if code := runApp([]string{"--agent", "test-agent", "--workspace", workspaceRoot}); code != 0 {
+		t.Fatalf("claim failed with exit code %d: %s", code, buf.String())
+	}
+	buf.Reset()
+
+	code := runApp([]string{"complete", "--agent", "test-agent", "--issue", "test-123", "--workspace", workspaceRoot, "--human"})
+	if code != 0 {
+		t.Fatalf("complete failed with exit code %d: %s", code, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Closed issue test-123") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
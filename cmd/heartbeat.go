@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// runHeartbeatApp is the entrypoint for `bd-claim heartbeat`.
+func runHeartbeatApp(args []string) int {
+	cfg, err := parseHeartbeatFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	result := runHeartbeat(cfg)
+	return outputResult(cfg, result)
+}
+
+func parseHeartbeatFlags(args []string) (config, error) {
+	cfg := config{command: "heartbeat"}
+	fs := flag.NewFlagSet("bd-claim heartbeat", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	registerCommonFlags(fs, &cfg)
+	fs.StringVar(&cfg.issueID, "issue", "", "ID of the claimed issue still being worked on (required)")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+func runHeartbeat(cfg config) application.ClaimIssueResult {
+	agent, err := parseAgent(cfg.agent)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	if cfg.issueID == "" {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--issue flag is required")
+	}
+
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	defer repo.Close()
+
+	clock := infrastructure.NewSystemClock()
+	useCase := application.NewHeartbeatUseCase(repo, clock, logger)
+
+	req := application.HeartbeatRequest{
+		Agent:   agent,
+		IssueID: domain.IssueId(cfg.issueID),
+	}
+
+	return useCase.Execute(context.Background(), req)
+}
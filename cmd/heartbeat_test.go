@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/application"
+)
+
+func TestParseHeartbeatFlags(t *testing.T) {
+	cfg, err := parseHeartbeatFlags([]string{"--agent", "test-agent", "--issue", "bd-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "heartbeat" {
+		t.Errorf("expected command 'heartbeat', got '%s'", cfg.command)
+	}
+	if cfg.agent != "test-agent" || cfg.issueID != "bd-1" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	if _, err := parseHeartbeatFlags([]string{"--reason", "x"}); err == nil {
+		t.Error("expected error for release-only flag")
+	}
+}
+
+func TestRunHeartbeat_MissingIssue(t *testing.T) {
+	result := runHeartbeat(config{agent: "test-agent"})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestRunHeartbeat_MissingAgent(t *testing.T) {
+	result := runHeartbeat(config{issueID: "bd-1"})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestRunApp_ClaimThenHeartbeat(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	if code := runApp([]string{"--agent", "test-agent", "--workspace", workspaceRoot, "--lease", "10m"}); code != 0 {
+		t.Fatalf("claim failed with exit code %d: %s", code, buf.String())
+	}
+	buf.Reset()
+
+	code := runApp([]string{"heartbeat", "--agent", "test-agent", "--issue", "test-123", "--workspace", workspaceRoot})
+	if code != 0 {
+		t.Fatalf("heartbeat failed with exit code %d: %s", code, buf.String())
+	}
+	var result application.ClaimIssueResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if result.Issue == nil || result.Issue.LastHeartbeatAt == nil || result.Issue.LeaseExpiresAt == nil {
+		t.Errorf("expected heartbeat and renewed lease, got %+v", result.Issue)
+	}
+	buf.Reset()
+
+	// Another agent has lost (never had) the claim
+	code = runApp([]string{"heartbeat", "--agent", "other-agent", "--issue", "test-123", "--workspace", workspaceRoot, "--human"})
+	if code != 1 {
+		t.Errorf("expected exit code 1 for non-owner, got %d", code)
+	}
+	if !strings.Contains(buf.String(), "LEASE_LOST") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// maxHTTPBody bounds the size of a JSON request body.
+const maxHTTPBody = 1 << 20
+
+// defaultReadyLimit is how many issues GET /v1/ready lists without ?limit.
+const defaultReadyLimit = 10
+
+// httpTokenEnv names the environment variable holding the HTTP API's bearer
+// token. Without it the token is kept in httpTokenName beside the database.
+const httpTokenEnv = "BD_CLAIM_HTTP_TOKEN"
+
+// httpTokenName is the token file created next to the Beads database.
+const httpTokenName = "bd-claim-http.token"
+
+// HTTP server timeouts. There is no write timeout: a --wait claim holds its
+// response open until an issue is claimed.
+const (
+	httpReadHeaderTimeout = 10 * time.Second
+	httpReadTimeout       = 30 * time.Second
+	httpIdleTimeout       = 2 * time.Minute
+)
+
+// claimParams is the JSON form of the claim command's flags, accepted by
+// POST /v1/claim and the claim_issue MCP tool.
+type claimParams struct {
+	Agent          string         `json:"agent"`
+	Labels         []string       `json:"labels"`
+	ExcludeLabels  []string       `json:"exclude_labels"`
+	Where          string         `json:"where"`
+	Types          []string       `json:"types"`
+	ExcludeTypes   []string       `json:"exclude_types"`
+	Parent         string         `json:"parent"`
+	DirectChildren bool           `json:"direct_children"`
+	Priority       string         `json:"priority"`
+	MaxPriority    string         `json:"max_priority"`
+	OnlyUnassigned bool           `json:"only_unassigned"`
+	Readiness      string         `json:"readiness"`
+	Strategy       string         `json:"strategy"`
+	LabelLimits    map[string]int `json:"label_limits"`
+	Exclusive      []string       `json:"exclusive_prefixes"`
+	PreferLabels   []string       `json:"prefer_labels"`
+	Lease          string         `json:"lease"`
+	MaxInProgress  *int           `json:"max_in_progress"`
+	Count          *int           `json:"count"`
+	Issue          string         `json:"issue"`
+	Wait           bool           `json:"wait"`
+	WaitTimeout    string         `json:"wait_timeout"`
+	PollInterval   string         `json:"poll_interval"`
+	DryRun         bool           `json:"dry_run"`
+}
+
+// config converts the parameters into the claim command's configuration.
+func (r claimParams) config() (config, error) {
+	cfg := config{
+		agent:          r.Agent,
+		labels:         r.Labels,
+		excludeLabels:  r.ExcludeLabels,
+		where:          r.Where,
+		types:          r.Types,
+		excludeTypes:   r.ExcludeTypes,
+		parent:         r.Parent,
+		directChildren: r.DirectChildren,
+		priority:       r.Priority,
+		maxPriority:    r.MaxPriority,
+		onlyUnassigned: r.OnlyUnassigned,
+		readiness:      r.Readiness,
+		strategy:       r.Strategy,
+		labelLimits:    labelLimitFlags(r.LabelLimits),
+		exclusive:      r.Exclusive,
+		preferLabels:   r.PreferLabels,
+		maxInProgress:  unsetMaxInProgress,
+		count:          1,
+		issueID:        r.Issue,
+		wait:           r.Wait,
+		pollInterval:   2 * time.Second,
+		dryRun:         r.DryRun,
+	}
+	if r.Count != nil {
+		if *r.Count < 1 {
+			return config{}, invalidArgument("count must be at least 1")
+		}
+		cfg.count = *r.Count
+	}
+	if r.MaxInProgress != nil {
+		cfg.maxInProgress = *r.MaxInProgress
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"lease", r.Lease, &cfg.lease},
+		{"wait_timeout", r.WaitTimeout, &cfg.waitTimeout},
+		{"poll_interval", r.PollInterval, &cfg.pollInterval},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return config{}, invalidArgument(fmt.Sprintf("invalid %s %q", d.name, d.value))
+		}
+		*d.dst = parsed
+	}
+
+	return cfg, nil
+}
+
+// readyParams selects the ready queue listed by GET /v1/ready and the
+// peek_ready MCP tool.
+type readyParams struct {
+	Agent          string         `json:"agent"`
+	Labels         []string       `json:"labels"`
+	ExcludeLabels  []string       `json:"exclude_labels"`
+	Where          string         `json:"where"`
+	Types          []string       `json:"types"`
+	ExcludeTypes   []string       `json:"exclude_types"`
+	Parent         string         `json:"parent"`
+	DirectChildren bool           `json:"direct_children"`
+	Priority       string         `json:"priority"`
+	MaxPriority    string         `json:"max_priority"`
+	OnlyUnassigned bool           `json:"only_unassigned"`
+	Readiness      string         `json:"readiness"`
+	Strategy       string         `json:"strategy"`
+	LabelLimits    map[string]int `json:"label_limits"`
+	Exclusive      []string       `json:"exclusive_prefixes"`
+	PreferLabels   []string       `json:"prefer_labels"`
+	Limit          *int           `json:"limit"`
+}
+
+// config converts the parameters into a dry-run claim of up to Limit issues.
+func (r readyParams) config() (config, error) {
+	cfg := config{
+		agent:          r.Agent,
+		labels:         r.Labels,
+		excludeLabels:  r.ExcludeLabels,
+		where:          r.Where,
+		types:          r.Types,
+		excludeTypes:   r.ExcludeTypes,
+		parent:         r.Parent,
+		directChildren: r.DirectChildren,
+		priority:       r.Priority,
+		maxPriority:    r.MaxPriority,
+		onlyUnassigned: r.OnlyUnassigned,
+		readiness:      r.Readiness,
+		strategy:       r.Strategy,
+		labelLimits:    labelLimitFlags(r.LabelLimits),
+		exclusive:      r.Exclusive,
+		preferLabels:   r.PreferLabels,
+		count:          defaultReadyLimit,
+		dryRun:         true,
+	}
+	if r.Limit != nil {
+		if *r.Limit < 1 {
+			return config{}, invalidArgument("limit must be a positive integer")
+		}
+		cfg.count = *r.Limit
+	}
+	return cfg, nil
+}
+
+// newHTTPHandler exposes the claim use case as a JSON API:
+//
+//	POST /v1/claim          claim issues (body: claimParams)
+//	POST /v1/claim/dry-run  show what a claim would take
+//	GET  /v1/ready          list the ready queue (?agent=&label=&type=&parent=&where=&label_limit=&prefer_label=&limit=...)
+//
+// Every request must carry token as a bearer token. Every response is a
+// ClaimIssueResult; its error code sets the HTTP status.
+func newHTTPHandler(
+	repo application.IssueRepositoryPort,
+	configs *infrastructure.ClaimConfigLoader,
+	token string,
+	logger application.LoggerPort,
+) http.Handler {
+	mux := http.NewServeMux()
+
+	claim := func(dryRun bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var body claimParams
+			decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHTTPBody))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&body); err != nil {
+				writeHTTPResult(w, errorResult("", domain.ErrCodeInvalidArgument, "invalid request body: "+err.Error()))
+				return
+			}
+
+			cfg, err := body.config()
+			if err != nil {
+				writeHTTPResult(w, handleDomainError(body.Agent, err))
+				return
+			}
+			cfg.dryRun = cfg.dryRun || dryRun
+
+			writeHTTPResult(w, claimForHTTP(r, cfg, configs, repo, logger))
+		}
+	}
+	mux.HandleFunc("POST /v1/claim", claim(false))
+	mux.HandleFunc("POST /v1/claim/dry-run", claim(true))
+
+	mux.HandleFunc("GET /v1/ready", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		params := readyParams{
+			Agent:         query.Get("agent"),
+			Labels:        query["label"],
+			ExcludeLabels: query["exclude_label"],
+			Where:         query.Get("where"),
+			Types:         query["type"],
+			ExcludeTypes:  query["exclude_type"],
+			Parent:        query.Get("parent"),
+			Priority:      query.Get("priority"),
+			MaxPriority:   query.Get("max_priority"),
+			Readiness:     query.Get("readiness"),
+			Strategy:      query.Get("strategy"),
+			Exclusive:     query["exclusive_prefix"],
+			PreferLabels:  query["prefer_label"],
+		}
+		params.OnlyUnassigned, _ = strconv.ParseBool(query.Get("only_unassigned"))
+		params.DirectChildren, _ = strconv.ParseBool(query.Get("direct_children"))
+		for _, s := range query["label_limit"] {
+			label, n, err := domain.ParseLabelLimit(s)
+			if err != nil {
+				writeHTTPResult(w, errorResult(params.Agent, domain.ErrCodeInvalidArgument, err.Error()))
+				return
+			}
+			if params.LabelLimits == nil {
+				params.LabelLimits = map[string]int{}
+			}
+			params.LabelLimits[label] = n
+		}
+		if limit := query.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil {
+				writeHTTPResult(w, errorResult(params.Agent, domain.ErrCodeInvalidArgument, "limit must be a positive integer"))
+				return
+			}
+			params.Limit = &n
+		}
+
+		cfg, err := params.config()
+		if err != nil {
+			writeHTTPResult(w, handleDomainError(params.Agent, err))
+			return
+		}
+		writeHTTPResult(w, claimForHTTP(r, cfg, configs, repo, logger))
+	})
+
+	return requireBearer(token, mux)
+}
+
+// requireBearer answers UNAUTHORIZED to requests that do not carry token as
+// a bearer token.
+func requireBearer(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if token == "" || subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bd-claim"`)
+			writeHTTPResult(w, errorResult("", domain.ErrCodeUnauthorized, "missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loadHTTPToken returns the HTTP API's bearer token and where it came from:
+// httpTokenEnv if set, or else the token file beside the database, which is
+// created with a random token on first use.
+func loadHTTPToken(dbPath string) (token, source string, err error) {
+	if token := strings.TrimSpace(os.Getenv(httpTokenEnv)); token != "" {
+		return token, httpTokenEnv, nil
+	}
+
+	path := filepath.Join(filepath.Dir(dbPath), httpTokenName)
+	token, err = readHTTPToken(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		token, err = createHTTPToken(path)
+		if errors.Is(err, fs.ErrExist) {
+			// Another server created it first
+			token, err = readHTTPToken(path)
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return token, path, nil
+}
+
+// readHTTPToken reads a token file, refusing one other users could read.
+func readHTTPToken(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("token file %s is accessible to other users; chmod it to 0600", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// createHTTPToken writes a new random token to path, readable by its owner only.
+func createHTTPToken(path string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+	return token, f.Close()
+}
+
+// claimForHTTP validates and runs a claim for an HTTP request under the
+// current claim config. A client that disconnects interrupts its wait.
+func claimForHTTP(
+	r *http.Request,
+	cfg config,
+	configs *infrastructure.ClaimConfigLoader,
+	repo application.IssueRepositoryPort,
+	logger application.LoggerPort,
+) application.ClaimIssueResult {
+	cfg, err := applyCurrentClaimConfig(cfg, configs)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	req, err := buildClaimRequest(cfg)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	return executeClaim(r.Context(), cfg, req, repo, logger)
+}
+
+// httpStatuses maps claim error codes to HTTP statuses. Unlisted codes are
+// server errors.
+var httpStatuses = map[string]int{
+	string(domain.ErrCodeInvalidArgument): http.StatusBadRequest,
+	string(domain.ErrCodeUnauthorized):    http.StatusUnauthorized,
+	string(domain.ErrCodeNotFound):        http.StatusNotFound,
+	string(domain.ErrCodeNotOwner):        http.StatusForbidden,
+	string(domain.ErrCodeNotReady):        http.StatusConflict,
+	string(domain.ErrCodeAlreadyClaimed):  http.StatusConflict,
+	string(domain.ErrCodeLeaseLost):       http.StatusConflict,
+	string(domain.ErrCodeAlreadyClosed):   http.StatusConflict,
+	string(domain.ErrCodeNotClaimed):      http.StatusConflict,
+	string(domain.ErrCodeWIPLimitReached): http.StatusConflict,
+	string(domain.ErrCodeSQLiteBusy):      http.StatusServiceUnavailable,
+}
+
+// httpStatus returns the HTTP status for a claim result.
+func httpStatus(result application.ClaimIssueResult) int {
+	if result.Error == nil {
+		return http.StatusOK
+	}
+	if status, ok := httpStatuses[result.Error.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func writeHTTPResult(w http.ResponseWriter, result application.ClaimIssueResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(result))
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// testHTTPToken is the bearer token test servers require.
+const testHTTPToken = "test-token"
+
+// newTestHTTPServer serves the HTTP API for a test workspace.
+func newTestHTTPServer(t *testing.T, workspace string) *httptest.Server {
+	t.Helper()
+
+	logger := infrastructure.NewJSONLogger(infrastructure.LogLevelError)
+	cfg := config{workspace: workspace, timeoutMs: 1000}
+	dbPath, err := resolveDbPath(cfg, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, configs, err := openServerRepository(dbPath, cfg, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(newHTTPHandler(repo, configs, testHTTPToken, logger))
+	t.Cleanup(func() {
+		server.Close()
+		repo.Close()
+	})
+	return server
+}
+
+func doHTTP(t *testing.T, method, url, body string) (int, application.ClaimIssueResult) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testHTTPToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+
+	var result application.ClaimIssueResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	return resp.StatusCode, result
+}
+
+func TestHTTP_Claim(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+	insertIssue(t, tmpDir, "test-2", "Second", 2)
+	server := newTestHTTPServer(t, tmpDir)
+
+	status, result := doHTTP(t, "POST", server.URL+"/v1/claim/dry-run", `{"agent": "agent-a"}`)
+	if status != http.StatusOK || result.Issue == nil || result.Issue.Status != "open" {
+		t.Fatalf("unexpected dry-run: %d %+v", status, result)
+	}
+
+	status, result = doHTTP(t, "POST", server.URL+"/v1/claim", `{"agent": "agent-a", "lease": "10m"}`)
+	if status != http.StatusOK || result.Issue == nil || result.Issue.ID != "test-1" {
+		t.Fatalf("unexpected claim: %d %+v", status, result)
+	}
+	if result.Issue.Status != "in_progress" || result.Issue.LeaseExpiresAt == nil {
+		t.Errorf("expected a leased in_progress issue, got %+v", result.Issue)
+	}
+
+	status, result = doHTTP(t, "GET", server.URL+"/v1/ready?agent=agent-b", "")
+	if status != http.StatusOK || len(result.Issues) != 1 || result.Issues[0].ID != "test-2" {
+		t.Fatalf("unexpected ready list: %d %+v", status, result)
+	}
+
+	status, result = doHTTP(t, "POST", server.URL+"/v1/claim", `{"agent": "agent-b", "issue": "test-1"}`)
+	if status != http.StatusConflict || result.Error == nil || result.Error.Code != "ALREADY_CLAIMED" {
+		t.Errorf("expected 409 ALREADY_CLAIMED, got %d %+v", status, result)
+	}
+}
+
+func TestHTTP_ReloadsConfig(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+	insertIssue(t, tmpDir, "test-2", "Second", 2)
+	server := newTestHTTPServer(t, tmpDir)
+
+	status, result := doHTTP(t, "POST", server.URL+"/v1/claim", `{"agent": "agent-a"}`)
+	if status != http.StatusOK || result.Issue == nil {
+		t.Fatalf("unexpected claim: %d %+v", status, result)
+	}
+
+	// Edited while the server runs; the next request sees it
+	configPath := filepath.Join(tmpDir, ".beads", infrastructure.ClaimConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, result = doHTTP(t, "POST", server.URL+"/v1/claim", `{"agent": "agent-a"}`)
+	if result.Error == nil || result.Error.Code != "WIP_LIMIT_REACHED" {
+		t.Errorf("expected the new limit to apply, got %d %+v", status, result)
+	}
+}
+
+func TestHTTP_Validation(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	server := newTestHTTPServer(t, tmpDir)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"missing agent", "POST", "/v1/claim", `{}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"invalid agent", "POST", "/v1/claim", `{"agent": "bad agent!"}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"unknown field", "POST", "/v1/claim", `{"agent": "a", "colour": "red"}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"malformed body", "POST", "/v1/claim", `{`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"bad lease", "POST", "/v1/claim", `{"agent": "a", "lease": "soon"}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"bad priority", "POST", "/v1/claim", `{"agent": "a", "priority": "urgent"}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"unknown issue", "POST", "/v1/claim", `{"agent": "a", "issue": "nope"}`, http.StatusNotFound, "NOT_FOUND"},
+		{"negative label limit", "POST", "/v1/claim", `{"agent": "a", "label_limits": {"release": -1}}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"zero count", "POST", "/v1/claim", `{"agent": "a", "count": 0}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"bad where", "POST", "/v1/claim", `{"agent": "a", "where": "label:a or"}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"bad limit", "GET", "/v1/ready?agent=a&limit=0", "", http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"bad label limit", "GET", "/v1/ready?agent=a&label_limit=release", "", http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"ready without agent", "GET", "/v1/ready", "", http.StatusBadRequest, "INVALID_ARGUMENT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := doHTTP(t, tt.method, server.URL+tt.path, tt.body)
+			if status != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, status)
+			}
+			if result.Status != "error" || result.Error == nil || result.Error.Code != tt.code {
+				t.Errorf("expected %s error, got %+v", tt.code, result)
+			}
+		})
+	}
+}
+
+func TestHTTP_Unauthorized(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+	server := newTestHTTPServer(t, tmpDir)
+
+	for _, auth := range []string{"", "Bearer wrong-token", testHTTPToken} {
+		req, _ := http.NewRequest("POST", server.URL+"/v1/claim", bytes.NewBufferString(`{"agent": "agent-a"}`))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result application.ClaimIssueResult
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized || result.Error == nil || result.Error.Code != "UNAUTHORIZED" {
+			t.Errorf("Authorization %q: expected 401 UNAUTHORIZED, got %d %+v", auth, resp.StatusCode, result)
+		}
+		if resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("Authorization %q: expected a WWW-Authenticate challenge", auth)
+		}
+	}
+
+	// Nothing was claimed
+	status, result := doHTTP(t, "GET", server.URL+"/v1/ready?agent=agent-b", "")
+	if status != http.StatusOK || len(result.Issues) != 1 {
+		t.Errorf("expected test-1 still ready, got %d %+v", status, result)
+	}
+}
+
+func TestLoadHTTPToken(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "beads.db")
+	t.Setenv(httpTokenEnv, "")
+
+	token, source, err := loadHTTPToken(dbPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, httpTokenName)
+	if token == "" || source != path {
+		t.Fatalf("expected a token created in %s, got %q from %s", path, token, source)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a 0600 token file, got %v (%v)", info, err)
+	}
+
+	// The file is reused by the next server
+	if again, _, err := loadHTTPToken(dbPath); err != nil || again != token {
+		t.Errorf("expected the same token, got %q (%v)", again, err)
+	}
+
+	// The environment wins
+	t.Setenv(httpTokenEnv, "from-env")
+	if token, source, err := loadHTTPToken(dbPath); err != nil || token != "from-env" || source != httpTokenEnv {
+		t.Errorf("expected the environment token, got %q from %s (%v)", token, source, err)
+	}
+	t.Setenv(httpTokenEnv, "")
+
+	// A token other users can read is refused
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadHTTPToken(dbPath); err == nil {
+		t.Error("expected a world-readable token file to be refused")
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{"", http.StatusOK},
+		{"INVALID_ARGUMENT", http.StatusBadRequest},
+		{"NOT_FOUND", http.StatusNotFound},
+		{"NOT_OWNER", http.StatusForbidden},
+		{"NOT_READY", http.StatusConflict},
+		{"ALREADY_CLAIMED", http.StatusConflict},
+		{"LEASE_LOST", http.StatusConflict},
+		{"SQLITE_BUSY", http.StatusServiceUnavailable},
+		{"SCHEMA_INCOMPATIBLE", http.StatusInternalServerError},
+		{"UNEXPECTED", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		result := application.ClaimIssueResult{Status: "ok"}
+		if tt.code != "" {
+			result.Status = "error"
+			result.Error = &application.ClaimErrorDTO{Code: tt.code}
+		}
+		if got := httpStatus(result); got != tt.expected {
+			t.Errorf("%q: expected %d, got %d", tt.code, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "net"
+
+// listenPrivate listens on a Unix socket. Without a umask to narrow its
+// permissions the caller's chmod is all that restricts it.
+func listenPrivate(socket string) (net.Listener, error) {
+	return net.Listen("unix", socket)
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate listens on a Unix socket that only the current user can
+// connect to from the moment it exists. The umask is process-wide, so this
+// must run before the server starts other goroutines that create files.
+func listenPrivate(socket string) (net.Listener, error) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", socket)
+}
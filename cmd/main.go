@@ -4,10 +4,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ccheney/bd-claim/internal/application"
 	"github.com/ccheney/bd-claim/internal/domain"
@@ -37,21 +45,56 @@ func (a *arrayFlag) Set(value string) error {
 	return nil
 }
 
+// unsetMaxInProgress marks a --max-in-progress left to the workspace config.
+const unsetMaxInProgress = -1
+
 type config struct {
-	agent          string
-	labels         arrayFlag
-	excludeLabels  arrayFlag
-	minPriority    int
-	onlyUnassigned bool
-	workspace      string
-	dbPath         string
-	dryRun         bool
-	jsonOutput     bool
-	pretty         bool
-	human          bool
-	timeoutMs      int
-	logLevel       string
-	showVersion    bool
+	command          string
+	args             []string
+	issueID          string
+	reason           string
+	agent            string
+	transferTo       string
+	labels           arrayFlag
+	excludeLabels    arrayFlag
+	where            string
+	types            arrayFlag
+	excludeTypes     arrayFlag
+	typeOrder        []string
+	parent           string
+	directChildren   bool
+	labelLimits      arrayFlag
+	labelLimitConfig map[string]int
+	exclusive        arrayFlag
+	exclusiveConfig  []string
+	preferLabels     arrayFlag
+	profiles         infrastructure.ClaimProfiles
+	minPriority      string
+	maxPriority      string
+	priority         string
+	readiness        string
+	strategy         string
+	onlyUnassigned   bool
+	lease            time.Duration
+	maxInProgress    int
+	count            int
+	wait             bool
+	waitTimeout      time.Duration
+	pollInterval     time.Duration
+	workspace        string
+	dbPath           string
+	socket           string
+	httpAddr         string
+	exec             []string
+	poolSize         int
+	agentPrefix      string
+	dryRun           bool
+	jsonOutput       bool
+	pretty           bool
+	human            bool
+	timeoutMs        int
+	logLevel         string
+	showVersion      bool
 	skipVersionCheck bool
 }
 
@@ -60,7 +103,26 @@ func main() {
 	osExit(exitCode)
 }
 
+// commands maps subcommand names to their entrypoints. Any other first
+// argument is treated as a flag of the default claim command.
+var commands = map[string]func(args []string) int{
+	"release":   runReleaseApp,
+	"complete":  runCompleteApp,
+	"transfer":  runTransferApp,
+	"heartbeat": runHeartbeatApp,
+	"serve":     runServeApp,
+	"mcp":       runMCPApp,
+	"run":       runSuperviseApp,
+	"pool":      runPoolApp,
+}
+
 func runApp(args []string) int {
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			return command(args[1:])
+		}
+	}
+
 	cfg, err := parseFlagsFromArgs(args)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
@@ -77,53 +139,292 @@ func runApp(args []string) int {
 }
 
 func parseFlagsFromArgs(args []string) (config, error) {
-	cfg := config{}
+	cfg := config{args: args}
 	fs := flag.NewFlagSet("bd-claim", flag.ContinueOnError)
 	fs.SetOutput(io.Discard) // Suppress default usage output
 
+	registerCommonFlags(fs, &cfg)
+	registerFilterFlags(fs, &cfg)
+	fs.DurationVar(&cfg.lease, "lease", 0, "Claim lease (e.g. 30m); once expired other agents may reclaim the issue")
+	fs.IntVar(&cfg.maxInProgress, "max-in-progress", unsetMaxInProgress, "Refuse to claim once the agent holds this many in-progress issues; 0 means no limit (default: max_in_progress in .beads/bd-claim.json)")
+	fs.StringVar(&cfg.issueID, "issue", "", "Claim this issue only, if it is ready and matches the filters")
+	fs.IntVar(&cfg.count, "count", 1, "Claim up to this many issues at once, atomically")
+	fs.BoolVar(&cfg.wait, "wait", false, "Keep polling until an issue is claimed, the wait times out, or SIGINT/SIGTERM arrives")
+	fs.DurationVar(&cfg.waitTimeout, "wait-timeout", 0, "Give up waiting after this long (e.g. 10m); 0 waits indefinitely")
+	fs.DurationVar(&cfg.pollInterval, "poll-interval", 2*time.Second, "Average delay between claim attempts while waiting (jittered)")
+	fs.BoolVar(&cfg.dryRun, "dry-run", false, "Show which issue would be claimed without updating")
+	fs.BoolVar(&cfg.showVersion, "version", false, "Show version")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+	if cfg.count < 1 {
+		return config{}, errors.New("--count must be at least 1")
+	}
+
+	return cfg, nil
+}
+
+// registerCommonFlags registers the flags shared by every command.
+func registerCommonFlags(fs *flag.FlagSet, cfg *config) {
 	fs.StringVar(&cfg.agent, "agent", "", "Agent name (required)")
-	fs.Var(&cfg.labels, "label", "Include issues with this label (repeatable)")
-	fs.Var(&cfg.excludeLabels, "exclude-label", "Exclude issues with this label (repeatable)")
-	fs.IntVar(&cfg.minPriority, "min-priority", -1, "Minimum priority level (0=low, 1=medium, 2=high)")
-	fs.BoolVar(&cfg.onlyUnassigned, "only-unassigned", false, "Only consider unassigned issues")
 	fs.StringVar(&cfg.workspace, "workspace", "", "Override workspace root path")
 	fs.StringVar(&cfg.dbPath, "db", "", "Override database path")
-	fs.BoolVar(&cfg.dryRun, "dry-run", false, "Show which issue would be claimed without updating")
 	fs.BoolVar(&cfg.jsonOutput, "json", true, "Output in JSON format (default)")
 	fs.BoolVar(&cfg.pretty, "pretty", false, "Pretty-print JSON output")
 	fs.BoolVar(&cfg.human, "human", false, "Human-friendly output")
 	fs.IntVar(&cfg.timeoutMs, "timeout-ms", 3000, "Database busy timeout in milliseconds")
 	fs.StringVar(&cfg.logLevel, "log-level", "error", "Log level (debug, info, warn, error)")
-	fs.BoolVar(&cfg.showVersion, "version", false, "Show version")
 	fs.BoolVar(&cfg.skipVersionCheck, "skip-version-check", false, "Skip database version compatibility check")
+}
 
-	if err := fs.Parse(args); err != nil {
-		return config{}, err
+// registerFilterFlags registers the flags that choose which issues to claim.
+func registerFilterFlags(fs *flag.FlagSet, cfg *config) {
+	fs.Var(&cfg.labels, "label", "Include issues with this label (repeatable)")
+	fs.Var(&cfg.excludeLabels, "exclude-label", "Exclude issues with this label (repeatable)")
+	fs.Var(&cfg.types, "type", "Only claim issues of this issue type, e.g. bug (repeatable)")
+	fs.Var(&cfg.excludeTypes, "exclude-type", "Skip issues of this issue type (repeatable)")
+	fs.StringVar(&cfg.parent, "parent", "", "Only claim descendants of this issue through parent-child dependencies")
+	fs.BoolVar(&cfg.directChildren, "direct-children", false, "With --parent, only claim its immediate children")
+	fs.StringVar(&cfg.where, "where", "", "Only claim issues matching a label expression, e.g. 'label:frontend or label:docs and not label:blocked'")
+	fs.StringVar(&cfg.minPriority, "min-priority", "", "Minimum urgency: only claim P0 through this priority (alias for --max-priority)")
+	fs.StringVar(&cfg.maxPriority, "max-priority", "", "Least urgent priority to claim (0/P0=critical ... 4/P4=backlog)")
+	fs.StringVar(&cfg.priority, "priority", "", "Only claim this priority or inclusive range (e.g. P1, 0-2)")
+	fs.BoolVar(&cfg.onlyUnassigned, "only-unassigned", false, "Only consider unassigned issues")
+	fs.StringVar(&cfg.readiness, "readiness", "auto", "How to decide an issue is unblocked: auto, cache (blocked_issues_cache) or dependencies")
+	fs.StringVar(&cfg.strategy, "strategy", "priority", "Claim order: priority, or critical-path to claim issues that unblock the most work first")
+	fs.Var(&cfg.labelLimits, "label-limit", "Skip issues whose label already has N issues in progress across all agents, as label=N (repeatable; 0 lifts a configured limit)")
+	fs.Var(&cfg.preferLabels, "prefer-label", "Claim issues with this label first within a priority (repeatable)")
+	fs.Var(&cfg.exclusive, "exclusive-prefix", "Skip issues sharing a label with this prefix (e.g. component:) with an issue in progress (repeatable)")
+}
+
+func run(cfg config) application.ClaimIssueResult {
+	// Reject bad flags before looking for a server or database
+	if _, err := buildClaimRequest(cfg); err != nil {
+		return handleDomainError(cfg.agent, err)
 	}
 
-	return cfg, nil
+	// Set up logger
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	// Waiting claims stop on SIGINT/SIGTERM
+	ctx := context.Background()
+	if cfg.wait {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
+
+	// Hand the claim to a running `bd-claim serve` when there is one
+	if result, ok := claimViaServer(ctx, cfg, logger); ok {
+		return result
+	}
+
+	repo, claimConfig, err := openClaimRepository(cfg, logger)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	defer repo.Close()
+
+	cfg = applyClaimConfig(cfg, claimConfig)
+	req, err := buildClaimRequest(cfg)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	return executeClaim(ctx, cfg, req, repo, logger)
 }
 
-func run(cfg config) application.ClaimIssueResult {
-	// Validate agent name
-	if cfg.agent == "" {
-		return errorResult("", domain.ErrCodeInvalidArgument, "--agent flag is required")
+// buildClaimRequest validates the claim flags and turns them into a use case request.
+func buildClaimRequest(cfg config) (application.ClaimIssueRequest, error) {
+	agent, err := parseAgent(cfg.agent)
+	if err != nil {
+		return application.ClaimIssueRequest{}, err
 	}
 
-	agent, err := domain.NewAgentName(cfg.agent)
+	filters, err := buildFilters(cfg)
 	if err != nil {
-		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, err.Error())
+		return application.ClaimIssueRequest{}, invalidArgument(err.Error())
 	}
 
-	// Set up logger
-	logLevel := parseLogLevel(cfg.logLevel)
-	logger := infrastructure.NewJSONLogger(logLevel)
+	opts := domain.ClaimOptions{Lease: cfg.lease, MaxInProgress: cfg.maxInProgress}
+	if opts.MaxInProgress == unsetMaxInProgress {
+		opts.MaxInProgress = 0
+	}
+	if err := opts.Validate(); err != nil {
+		return application.ClaimIssueRequest{}, invalidArgument(err.Error())
+	}
+
+	// A zero count is left unset by callers building the config directly
+	// and claims one issue, as in ClaimIssueRequest
+	if cfg.count < 0 {
+		return application.ClaimIssueRequest{}, invalidArgument("--count cannot be negative")
+	}
+	if cfg.wait && cfg.waitTimeout < 0 {
+		return application.ClaimIssueRequest{}, invalidArgument("--wait-timeout cannot be negative")
+	}
+
+	return application.ClaimIssueRequest{
+		Agent:     agent,
+		Filters:   filters,
+		Options:   opts,
+		DryRun:    cfg.dryRun,
+		TimeoutMs: cfg.timeoutMs,
+		Count:     cfg.count,
+		IssueID:   domain.IssueId(cfg.issueID),
+	}, nil
+}
+
+// executeClaim runs the claim use case against an open repository, waiting
+// if asked to. ctx bounds the wait on top of --wait-timeout.
+func executeClaim(
+	ctx context.Context,
+	cfg config,
+	req application.ClaimIssueRequest,
+	repo application.IssueRepositoryPort,
+	logger application.LoggerPort,
+) application.ClaimIssueResult {
+	clock := infrastructure.NewSystemClock()
+	useCase := application.NewClaimIssueUseCase(repo, clock, logger)
+
+	if !cfg.wait {
+		return useCase.Execute(ctx, req)
+	}
+
+	if cfg.waitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.waitTimeout)
+		defer cancel()
+	}
+	return useCase.ExecuteWait(ctx, req, application.WaitOptions{PollInterval: cfg.pollInterval})
+}
+
+func invalidArgument(message string) error {
+	return &domain.ClaimFailed{
+		ErrorCode:  domain.ErrCodeInvalidArgument,
+		Message:    message,
+		OccurredAt: domain.Now(),
+	}
+}
 
-	// Discover workspace
+// parseAgent validates the --agent flag.
+func parseAgent(name string) (domain.AgentName, error) {
+	if name == "" {
+		return "", &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "--agent flag is required",
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	agent, err := domain.NewAgentName(name)
+	if err != nil {
+		return "", &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return agent, nil
+}
+
+// openRepository discovers the Beads database, opens it and checks version
+// compatibility. The caller owns closing the returned repository.
+func openRepository(cfg config, logger application.LoggerPort) (*infrastructure.SQLiteIssueRepository, error) {
+	dbPath, err := resolveDbPath(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return openRepositoryAt(dbPath, cfg, logger)
+}
+
+// openRepositoryAt opens the database at an already resolved dbPath and
+// checks version compatibility.
+func openRepositoryAt(dbPath string, cfg config, logger application.LoggerPort) (*infrastructure.SQLiteIssueRepository, error) {
+	repo, err := infrastructure.NewSQLiteIssueRepository(dbPath, cfg.timeoutMs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check version compatibility
+	if !cfg.skipVersionCheck {
+		if err := repo.CheckVersionCompatibility(context.Background()); err != nil {
+			logger.Warn("version_check_failed", map[string]interface{}{
+				"error":       err.Error(),
+				"min_version": infrastructure.MinCompatibleBdVersion,
+			})
+			repo.Close()
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// openClaimRepository opens the database like openRepository and loads the
+// claim config kept beside it.
+func openClaimRepository(cfg config, logger application.LoggerPort) (*infrastructure.SQLiteIssueRepository, infrastructure.ClaimConfig, error) {
+	dbPath, err := resolveDbPath(cfg, logger)
+	if err != nil {
+		return nil, infrastructure.ClaimConfig{}, err
+	}
+
+	claimConfig, err := infrastructure.LoadClaimConfig(dbPath)
+	if err != nil {
+		return nil, infrastructure.ClaimConfig{}, err
+	}
+
+	repo, err := openRepositoryAt(dbPath, cfg, logger)
+	if err != nil {
+		return nil, infrastructure.ClaimConfig{}, err
+	}
+	return repo, claimConfig, nil
+}
+
+// openServerRepository opens the database at dbPath, resolved once by the
+// caller, for a long-running server. The claim config beside it is checked
+// now and then reloaded by the returned loader as the file changes.
+func openServerRepository(dbPath string, cfg config, logger application.LoggerPort) (*infrastructure.SQLiteIssueRepository, *infrastructure.ClaimConfigLoader, error) {
+	configs := infrastructure.NewClaimConfigLoader(dbPath)
+	if _, err := configs.Load(); err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := openRepositoryAt(dbPath, cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, configs, nil
+}
+
+// applyCurrentClaimConfig applies the claim config as it is on disk now.
+func applyCurrentClaimConfig(cfg config, configs *infrastructure.ClaimConfigLoader) (config, error) {
+	claimConfig, err := configs.Load()
+	if err != nil {
+		return cfg, err
+	}
+	return applyClaimConfig(cfg, claimConfig), nil
+}
+
+// applyClaimConfig fills in claim settings left unset by flags or request
+// parameters from the workspace's claim config.
+func applyClaimConfig(cfg config, claimConfig infrastructure.ClaimConfig) config {
+	if cfg.maxInProgress == unsetMaxInProgress {
+		cfg.maxInProgress = claimConfig.MaxInProgress
+	}
+	cfg.labelLimitConfig = claimConfig.LabelLimits
+	cfg.exclusiveConfig = claimConfig.ExclusivePrefixes
+	cfg.profiles = claimConfig.Profiles
+	cfg.typeOrder = claimConfig.TypeOrder
+	return cfg
+}
+
+// resolveDbPath returns the --db override or the database discovered from
+// the workspace.
+func resolveDbPath(cfg config, logger application.LoggerPort) (string, error) {
 	workspaceAdapter := infrastructure.NewWorkspaceDiscoveryAdapter()
 
 	var dbPath string
-	var workspaceRoot string
 	if cfg.dbPath != "" {
 		dbPath = cfg.dbPath
 		logger.Debug("workspace_discovery", map[string]interface{}{
@@ -138,19 +439,22 @@ func run(cfg config) application.ClaimIssueResult {
 			var err error
 			cwd, err = os.Getwd()
 			if err != nil {
-				return errorResult(cfg.agent, domain.ErrCodeUnexpected, "failed to get working directory: "+err.Error())
+				return "", &domain.ClaimFailed{
+					ErrorCode:  domain.ErrCodeUnexpected,
+					Message:    "failed to get working directory: " + err.Error(),
+					OccurredAt: domain.Now(),
+				}
 			}
 		}
 
-		var err error
-		workspaceRoot, err = workspaceAdapter.FindWorkspaceRoot(cwd)
+		workspaceRoot, err := workspaceAdapter.FindWorkspaceRoot(cwd)
 		if err != nil {
-			return handleDomainError(cfg.agent, err)
+			return "", err
 		}
 
 		dbPath, err = workspaceAdapter.FindBeadsDbPath(workspaceRoot)
 		if err != nil {
-			return handleDomainError(cfg.agent, err)
+			return "", err
 		}
 
 		logger.Debug("workspace_discovery", map[string]interface{}{
@@ -161,47 +465,135 @@ func run(cfg config) application.ClaimIssueResult {
 		})
 	}
 
-	// Set up repository
-	repo, err := infrastructure.NewSQLiteIssueRepository(dbPath, cfg.timeoutMs)
-	if err != nil {
-		return handleDomainError(cfg.agent, err)
-	}
-	defer repo.Close()
+	return dbPath, nil
+}
 
-	// Check version compatibility
-	if !cfg.skipVersionCheck {
-		if err := repo.CheckVersionCompatibility(context.Background()); err != nil {
-			logger.Warn("version_check_failed", map[string]interface{}{
-				"error":       err.Error(),
-				"min_version": infrastructure.MinCompatibleBdVersion,
-			})
-			return handleDomainError(cfg.agent, err)
+// buildFilters translates CLI flags into domain claim filters.
+func buildFilters(cfg config) (domain.ClaimFilters, error) {
+	// The agent's profile adds to its flags; explicit priority flags win
+	profileName, profile, hasProfile := cfg.profiles.Match(strings.TrimSpace(cfg.agent))
+	if hasProfile {
+		cfg.labels = slices.Concat(arrayFlag(profile.Labels), cfg.labels)
+		cfg.excludeLabels = slices.Concat(arrayFlag(profile.ExcludeLabels), cfg.excludeLabels)
+		cfg.types = slices.Concat(arrayFlag(profile.Types), cfg.types)
+		cfg.excludeTypes = slices.Concat(arrayFlag(profile.ExcludeTypes), cfg.excludeTypes)
+		cfg.preferLabels = slices.Concat(arrayFlag(profile.PreferLabels), cfg.preferLabels)
+		cfg.onlyUnassigned = cfg.onlyUnassigned || profile.OnlyUnassigned
+		if cfg.priority == "" && cfg.maxPriority == "" && cfg.minPriority == "" {
+			cfg.priority = profile.Priority
+			cfg.maxPriority = profile.MaxPriority
 		}
 	}
 
-	// Build filters
 	filters := domain.NewClaimFilters()
 	filters.OnlyUnassigned = cfg.onlyUnassigned
 	filters.IncludeLabels = cfg.labels
 	filters.ExcludeLabels = cfg.excludeLabels
-	if cfg.minPriority >= 0 {
-		p := domain.Priority(cfg.minPriority)
-		filters.MinPriority = &p
+	filters.PreferLabels = cfg.preferLabels
+	filters.TypeOrder = cfg.typeOrder
+
+	if slices.Contains(cfg.types, "") || slices.Contains(cfg.excludeTypes, "") {
+		return filters, errors.New("issue type cannot be empty")
 	}
+	filters.IncludeTypes = cfg.types
+	filters.ExcludeTypes = cfg.excludeTypes
 
-	// Set up use case
-	clock := infrastructure.NewSystemClock()
-	useCase := application.NewClaimIssueUseCase(repo, clock, logger)
+	if cfg.directChildren && cfg.parent == "" {
+		return filters, errors.New("--direct-children requires --parent")
+	}
+	filters.Parent = domain.IssueId(strings.TrimSpace(cfg.parent))
+	filters.DirectChildren = cfg.directChildren
+	filters.Profile = profileName
+
+	// Both the profile's expression and --where must hold
+	for _, s := range []string{profile.Where, cfg.where} {
+		if s == "" {
+			continue
+		}
+		where, err := domain.ParseLabelExpr(s)
+		if err != nil {
+			return filters, err
+		}
+		if filters.Where != nil {
+			where = domain.AndExpr{X: filters.Where, Y: where}
+		}
+		filters.Where = where
+	}
 
-	// Execute
-	req := application.ClaimIssueRequest{
-		Agent:     agent,
-		Filters:   filters,
-		DryRun:    cfg.dryRun,
-		TimeoutMs: cfg.timeoutMs,
+	readiness, err := domain.ParseReadinessStrategy(cfg.readiness)
+	if err != nil {
+		return filters, err
+	}
+	filters.Readiness = readiness
+
+	strategy, err := domain.ParseSelectionStrategy(cfg.strategy)
+	if err != nil {
+		return filters, err
+	}
+	filters.Strategy = strategy
+
+	if cfg.priority != "" {
+		minP, maxP, err := domain.ParsePriorityRange(cfg.priority)
+		if err != nil {
+			return filters, err
+		}
+		filters.MinPriority = &minP
+		filters.MaxPriority = &maxP
+	}
+
+	// --min-priority is a minimum urgency, i.e. the largest priority number allowed
+	maxPriority := cfg.maxPriority
+	if maxPriority == "" {
+		maxPriority = cfg.minPriority
+	}
+	if maxPriority != "" {
+		p, err := domain.ParsePriority(maxPriority)
+		if err != nil {
+			return filters, err
+		}
+		if filters.MaxPriority == nil || p < *filters.MaxPriority {
+			filters.MaxPriority = &p
+		}
 	}
 
-	return useCase.Execute(context.Background(), req)
+	// --label-limit overrides the workspace's limit for that label; 0 lifts it
+	limits := maps.Clone(cfg.labelLimitConfig)
+	for _, s := range cfg.labelLimits {
+		label, n, err := domain.ParseLabelLimit(s)
+		if err != nil {
+			return filters, err
+		}
+		if limits == nil {
+			limits = map[string]int{}
+		}
+		limits[label] = n
+	}
+	maps.DeleteFunc(limits, func(_ string, n int) bool { return n == 0 })
+	if len(limits) > 0 {
+		filters.LabelLimits = limits
+	}
+
+	// --exclusive-prefix adds to the workspace's exclusive groups
+	for _, prefix := range append(slices.Clone(cfg.exclusiveConfig), cfg.exclusive...) {
+		if prefix == "" {
+			return filters, errors.New("exclusive prefix cannot be empty")
+		}
+		if !slices.Contains(filters.ExclusivePrefixes, prefix) {
+			filters.ExclusivePrefixes = append(filters.ExclusivePrefixes, prefix)
+		}
+	}
+
+	return filters, nil
+}
+
+// labelLimitFlags renders label limits as --label-limit values.
+func labelLimitFlags(limits map[string]int) arrayFlag {
+	var flags arrayFlag
+	for label, n := range limits {
+		flags = append(flags, fmt.Sprintf("%s=%d", label, n))
+	}
+	sort.Strings(flags)
+	return flags
 }
 
 func errorResult(agent string, code domain.ClaimErrorCode, message string) application.ClaimIssueResult {
@@ -231,9 +623,20 @@ func handleDomainError(agent string, err error) application.ClaimIssueResult {
 	return errorResult(agent, domain.ErrCodeUnexpected, err.Error())
 }
 
+// humanVerbs names the action reported in human output for each command.
+var humanVerbs = map[string]string{
+	"":          "Claimed",
+	"release":   "Released",
+	"complete":  "Closed",
+	"transfer":  "Transferred",
+	"heartbeat": "Heartbeat recorded for",
+	"run":       "Finished",
+	"pool":      "Finished",
+}
+
 func outputResult(cfg config, result application.ClaimIssueResult) int {
 	if cfg.human {
-		return outputHuman(result)
+		return outputHumanAs(humanVerbs[cfg.command], result)
 	}
 	return outputJSON(cfg, result)
 }
@@ -262,6 +665,10 @@ func outputJSON(cfg config, result application.ClaimIssueResult) int {
 }
 
 func outputHuman(result application.ClaimIssueResult) int {
+	return outputHumanAs("Claimed", result)
+}
+
+func outputHumanAs(verb string, result application.ClaimIssueResult) int {
 	if result.Status == "error" {
 		fmt.Fprintf(stdout, "Error: [%s] %s\n", result.Error.Code, result.Error.Message)
 		return 1
@@ -272,16 +679,42 @@ func outputHuman(result application.ClaimIssueResult) int {
 		return 0
 	}
 
-	fmt.Fprintf(stdout, "Claimed issue %s: %s\n", result.Issue.ID, result.Issue.Title)
-	fmt.Fprintf(stdout, "  Status: %s\n", result.Issue.Status)
-	fmt.Fprintf(stdout, "  Assignee: %s\n", *result.Issue.Assignee)
-	fmt.Fprintf(stdout, "  Priority: %d\n", result.Issue.Priority)
-	if len(result.Issue.Labels) > 0 {
-		fmt.Fprintf(stdout, "  Labels: %v\n", result.Issue.Labels)
+	if len(result.Issues) > 1 {
+		for _, issue := range result.Issues {
+			printHumanIssue(verb, issue)
+		}
+		return 0
 	}
+
+	printHumanIssue(verb, result.Issue)
 	return 0
 }
 
+func printHumanIssue(verb string, issue *application.IssueDTO) {
+	fmt.Fprintf(stdout, "%s issue %s: %s\n", verb, issue.ID, issue.Title)
+	fmt.Fprintf(stdout, "  Status: %s\n", issue.Status)
+	if issue.Assignee != nil {
+		fmt.Fprintf(stdout, "  Assignee: %s\n", *issue.Assignee)
+	}
+	priority := domain.Priority(issue.Priority)
+	fmt.Fprintf(stdout, "  Priority: %s (%s)\n", priority, priority.Name())
+	if len(issue.Labels) > 0 {
+		fmt.Fprintf(stdout, "  Labels: %v\n", issue.Labels)
+	}
+	if issue.Unblocks != nil {
+		fmt.Fprintf(stdout, "  Unblocks: %d issues\n", *issue.Unblocks)
+	}
+	if issue.LeaseExpiresAt != nil {
+		fmt.Fprintf(stdout, "  Lease expires: %s\n", *issue.LeaseExpiresAt)
+	}
+	if issue.LastHeartbeatAt != nil {
+		fmt.Fprintf(stdout, "  Last heartbeat: %s\n", *issue.LastHeartbeatAt)
+	}
+	if issue.ReclaimedFrom != nil {
+		fmt.Fprintf(stdout, "  Reclaimed from: %s\n", *issue.ReclaimedFrom)
+	}
+}
+
 func parseLogLevel(level string) infrastructure.LogLevel {
 	switch level {
 	case "debug":
@@ -5,9 +5,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ccheney/bd-claim/internal/application"
 	"github.com/ccheney/bd-claim/internal/domain"
@@ -105,6 +109,21 @@ func insertIssue(t *testing.T, workspaceRoot string, id, title string, priority
 	}
 }
 
+func insertLabel(t *testing.T, workspaceRoot string, issueID, label string) {
+	t.Helper()
+
+	dbPath := filepath.Join(workspaceRoot, ".beads", "beads.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`INSERT INTO labels (issue_id, label) VALUES (?, ?)`, issueID, label); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestParseFlagsFromArgs(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -155,8 +174,8 @@ func TestParseFlagsFromArgs(t *testing.T) {
 				if cfg.logLevel != "debug" {
 					t.Errorf("expected logLevel 'debug', got '%s'", cfg.logLevel)
 				}
-				if cfg.minPriority != 2 {
-					t.Errorf("expected minPriority 2, got %d", cfg.minPriority)
+				if cfg.minPriority != "2" {
+					t.Errorf("expected minPriority 2, got %s", cfg.minPriority)
 				}
 			},
 		},
@@ -176,6 +195,12 @@ func TestParseFlagsFromArgs(t *testing.T) {
 			expectError: true,
 			check:       nil,
 		},
+		{
+			name:        "zero count",
+			args:        []string{"--agent", "test", "--count", "0"},
+			expectError: true,
+			check:       nil,
+		},
 		{
 			name:        "workspace and db flags",
 			args:        []string{"--agent", "test", "--workspace", "/tmp/test", "--db", "/tmp/test.db"},
@@ -435,7 +460,7 @@ func TestRun_WithFilters(t *testing.T) {
 		workspace:   workspaceRoot,
 		timeoutMs:   1000,
 		labels:      []string{"backend"},
-		minPriority: 1,
+		minPriority: "1",
 	}
 
 	result := run(cfg)
@@ -446,6 +471,718 @@ func TestRun_WithFilters(t *testing.T) {
 	}
 }
 
+func TestBuildFilters_Priority(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config
+		min     *int
+		max     *int
+		wantErr bool
+	}{
+		{name: "no priority flags", cfg: config{}},
+		{name: "max priority", cfg: config{maxPriority: "1"}, max: intPtr(1)},
+		{name: "min priority is minimum urgency", cfg: config{minPriority: "P2"}, max: intPtr(2)},
+		{name: "max priority wins over min priority", cfg: config{minPriority: "3", maxPriority: "1"}, max: intPtr(1)},
+		{name: "exact priority", cfg: config{priority: "P0"}, min: intPtr(0), max: intPtr(0)},
+		{name: "priority range", cfg: config{priority: "1-3"}, min: intPtr(1), max: intPtr(3)},
+		{name: "range narrowed by max priority", cfg: config{priority: "1-3", maxPriority: "2"}, min: intPtr(1), max: intPtr(2)},
+		{name: "invalid priority", cfg: config{priority: "urgent"}, wantErr: true},
+		{name: "invalid max priority", cfg: config{maxPriority: "7"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := buildFilters(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildFilters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			checkPriority(t, "MinPriority", filters.MinPriority, tt.min)
+			checkPriority(t, "MaxPriority", filters.MaxPriority, tt.max)
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func checkPriority(t *testing.T, name string, got *domain.Priority, want *int) {
+	t.Helper()
+	if want == nil {
+		if got != nil {
+			t.Errorf("expected %s to be nil, got %d", name, *got)
+		}
+		return
+	}
+	if got == nil || int(*got) != *want {
+		t.Errorf("expected %s %d, got %v", name, *want, got)
+	}
+}
+
+func TestBuildFilters_Readiness(t *testing.T) {
+	filters, err := buildFilters(config{readiness: "dependencies"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filters.Readiness != domain.ReadinessDependencies {
+		t.Errorf("expected dependencies, got %q", filters.Readiness)
+	}
+
+	filters, err = buildFilters(config{})
+	if err != nil || filters.Readiness != domain.ReadinessAuto {
+		t.Errorf("expected auto by default, got %q (err %v)", filters.Readiness, err)
+	}
+
+	if _, err := buildFilters(config{readiness: "graph"}); err == nil {
+		t.Error("expected error for unknown readiness strategy")
+	}
+}
+
+func TestBuildFilters_LabelLimits(t *testing.T) {
+	cfg := config{
+		labelLimitConfig: map[string]int{"db-migration": 2, "release": 1},
+		labelLimits:      arrayFlag{"release=0", "docs=3"},
+	}
+
+	filters, err := buildFilters(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"db-migration": 2, "docs": 3}
+	if !maps.Equal(filters.LabelLimits, want) {
+		t.Errorf("expected %v, got %v", want, filters.LabelLimits)
+	}
+	if cfg.labelLimitConfig["release"] != 1 {
+		t.Error("expected the workspace config to be left untouched")
+	}
+
+	if filters, err := buildFilters(config{}); err != nil || filters.LabelLimits != nil {
+		t.Errorf("expected no label limits by default, got %v (err %v)", filters.LabelLimits, err)
+	}
+
+	if _, err := buildFilters(config{labelLimits: arrayFlag{"release"}}); err == nil {
+		t.Error("expected error for a label limit without N")
+	}
+}
+
+func TestRun_LabelLimit(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "rel-1", "Release one", 1)
+	insertIssue(t, workspaceRoot, "rel-2", "Release two", 2)
+	insertIssue(t, workspaceRoot, "other", "Other", 3)
+	insertLabel(t, workspaceRoot, "rel-1", "release")
+	insertLabel(t, workspaceRoot, "rel-2", "release")
+
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"label_limits": {"release": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(agent string, args ...string) application.ClaimIssueResult {
+		cfg, err := parseFlagsFromArgs(append([]string{"--agent", agent, "--workspace", workspaceRoot}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return run(cfg)
+	}
+
+	if result := claim("agent-a"); result.Issue == nil || result.Issue.ID != "rel-1" {
+		t.Fatalf("expected rel-1 to be claimed, got %+v", result)
+	}
+	result := claim("agent-b")
+	if result.Issue == nil || result.Issue.ID != "other" {
+		t.Fatalf("expected rel-2 to be skipped, got %+v", result)
+	}
+	if result.Filters == nil || result.Filters.LabelLimits["release"] != 1 {
+		t.Errorf("expected the label limit in the filters, got %+v", result.Filters)
+	}
+
+	if result := claim("agent-c", "--label-limit", "release=2"); result.Issue == nil || result.Issue.ID != "rel-2" {
+		t.Errorf("expected the flag to raise the limit, got %+v", result)
+	}
+}
+
+func TestBuildFilters_ExclusivePrefixes(t *testing.T) {
+	cfg := config{
+		exclusiveConfig: []string{"component:"},
+		exclusive:       arrayFlag{"area:", "component:"},
+	}
+
+	filters, err := buildFilters(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"component:", "area:"}
+	if !slices.Equal(filters.ExclusivePrefixes, want) {
+		t.Errorf("expected %v, got %v", want, filters.ExclusivePrefixes)
+	}
+
+	if _, err := buildFilters(config{exclusive: arrayFlag{""}}); err == nil {
+		t.Error("expected error for an empty exclusive prefix")
+	}
+}
+
+func TestRun_ExclusivePrefix(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "api-1", "API one", 1)
+	insertIssue(t, workspaceRoot, "api-2", "API two", 2)
+	insertIssue(t, workspaceRoot, "other", "Other", 3)
+	insertLabel(t, workspaceRoot, "api-1", "component:api")
+	insertLabel(t, workspaceRoot, "api-2", "component:api")
+
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"exclusive_prefixes": ["component:"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(agent string) application.ClaimIssueResult {
+		cfg, err := parseFlagsFromArgs([]string{"--agent", agent, "--workspace", workspaceRoot})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return run(cfg)
+	}
+
+	if result := claim("agent-a"); result.Issue == nil || result.Issue.ID != "api-1" {
+		t.Fatalf("expected api-1 to be claimed, got %+v", result)
+	}
+	result := claim("agent-b")
+	if result.Issue == nil || result.Issue.ID != "other" {
+		t.Fatalf("expected api-2 to be skipped, got %+v", result)
+	}
+	if result.Filters == nil || !slices.Equal(result.Filters.ExclusivePrefixes, []string{"component:"}) {
+		t.Errorf("expected the exclusive prefix in the filters, got %+v", result.Filters)
+	}
+}
+
+func TestBuildFilters_Profile(t *testing.T) {
+	profiles := infrastructure.ClaimProfiles{
+		"fe-*": {
+			Labels:        []string{"frontend"},
+			ExcludeLabels: []string{"blocked-external"},
+			MaxPriority:   "P2",
+			PreferLabels:  []string{"a11y"},
+		},
+	}
+
+	filters, err := buildFilters(config{agent: "fe-1", profiles: profiles, labels: arrayFlag{"web"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filters.Profile != "fe-*" {
+		t.Errorf("expected profile fe-*, got %q", filters.Profile)
+	}
+	if !slices.Equal(filters.IncludeLabels, []string{"frontend", "web"}) {
+		t.Errorf("expected profile and flag labels, got %v", filters.IncludeLabels)
+	}
+	if !slices.Equal(filters.ExcludeLabels, []string{"blocked-external"}) || !slices.Equal(filters.PreferLabels, []string{"a11y"}) {
+		t.Errorf("expected profile exclusions and preferences, got %+v", filters)
+	}
+	if filters.MaxPriority == nil || *filters.MaxPriority != domain.PriorityMedium {
+		t.Errorf("expected profile max priority P2, got %v", filters.MaxPriority)
+	}
+
+	// An explicit priority flag replaces the profile's bounds
+	filters, err = buildFilters(config{agent: "fe-1", profiles: profiles, priority: "P3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filters.MinPriority == nil || *filters.MinPriority != domain.PriorityLow || *filters.MaxPriority != domain.PriorityLow {
+		t.Errorf("expected --priority P3 to win, got %v-%v", filters.MinPriority, filters.MaxPriority)
+	}
+
+	filters, err = buildFilters(config{agent: "go-1", profiles: profiles})
+	if err != nil || filters.Profile != "" || filters.IncludeLabels != nil {
+		t.Errorf("expected no profile for go-1, got %+v (err %v)", filters, err)
+	}
+}
+
+func TestRun_Profile(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "backend", "Backend", 1)
+	insertIssue(t, workspaceRoot, "frontend", "Frontend", 2)
+	insertLabel(t, workspaceRoot, "frontend", "frontend")
+
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"profiles": {"fe-1": {"labels": ["frontend"]}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "fe-1", "--workspace", workspaceRoot})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := run(cfg)
+	if result.Issue == nil || result.Issue.ID != "frontend" {
+		t.Fatalf("expected the profile to route fe-1 to frontend, got %+v", result)
+	}
+	if result.Filters == nil || result.Filters.Profile != "fe-1" || !slices.Equal(result.Filters.IncludeLabels, []string{"frontend"}) {
+		t.Errorf("expected the applied profile in the filters, got %+v", result.Filters)
+	}
+}
+
+func TestBuildFilters_Where(t *testing.T) {
+	profiles := infrastructure.ClaimProfiles{
+		"fe-1": {Where: "label:frontend or label:docs"},
+	}
+
+	filters, err := buildFilters(config{agent: "fe-1", profiles: profiles, where: "not label:blocked"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "(label:frontend or label:docs) and not label:blocked"
+	if filters.Where == nil || filters.Where.String() != want {
+		t.Errorf("expected the profile and flag expressions combined as %q, got %v", want, filters.Where)
+	}
+
+	if filters, err := buildFilters(config{}); err != nil || filters.Where != nil {
+		t.Errorf("expected no expression by default, got %v (err %v)", filters.Where, err)
+	}
+
+	if _, err := buildFilters(config{where: "label:frontend and"}); err == nil {
+		t.Error("expected error for an incomplete expression")
+	}
+}
+
+func TestRun_Where(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "backend", "Backend", 0)
+	insertIssue(t, workspaceRoot, "docs", "Docs", 1)
+	insertIssue(t, workspaceRoot, "frontend", "Frontend", 2)
+	insertLabel(t, workspaceRoot, "docs", "docs")
+	insertLabel(t, workspaceRoot, "frontend", "frontend")
+
+	cfg, err := parseFlagsFromArgs([]string{
+		"--agent", "fe-1", "--workspace", workspaceRoot, "--count", "5",
+		"--where", "label:frontend OR label:docs",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := run(cfg)
+
+	// One call takes both kinds of work, most urgent first
+	if len(result.Issues) != 2 || result.Issues[0].ID != "docs" || result.Issues[1].ID != "frontend" {
+		t.Fatalf("expected docs then frontend, got %+v", result)
+	}
+	if result.Filters == nil || result.Filters.Where != "label:frontend or label:docs" {
+		t.Errorf("expected the expression in the filters, got %+v", result.Filters)
+	}
+}
+
+func TestBuildFilters_IssueTypes(t *testing.T) {
+	profiles := infrastructure.ClaimProfiles{
+		"planner": {Types: []string{"epic", "feature"}},
+	}
+
+	filters, err := buildFilters(config{
+		agent:        "planner",
+		profiles:     profiles,
+		excludeTypes: arrayFlag{"chore"},
+		typeOrder:    []string{"epic"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(filters.IncludeTypes, []string{"epic", "feature"}) || !slices.Equal(filters.ExcludeTypes, []string{"chore"}) {
+		t.Errorf("expected profile types and flag exclusions, got %v and %v", filters.IncludeTypes, filters.ExcludeTypes)
+	}
+	if !slices.Equal(filters.TypeOrder, []string{"epic"}) {
+		t.Errorf("expected the configured type order, got %v", filters.TypeOrder)
+	}
+
+	if _, err := buildFilters(config{types: arrayFlag{""}}); err == nil {
+		t.Error("expected error for an empty issue type")
+	}
+}
+
+func TestRun_IssueType(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "chore", "Chore", 1)
+	insertIssue(t, workspaceRoot, "bug", "Bug", 1)
+	insertIssue(t, workspaceRoot, "feature", "Feature", 1)
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"chore", "bug", "feature"} {
+		if _, err := db.Exec(`UPDATE issues SET issue_type = ? WHERE id = ?`, id, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`UPDATE issues SET created_at = datetime('now', '-1 day') WHERE id = 'chore'`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	claim := func(args ...string) application.ClaimIssueResult {
+		cfg, err := parseFlagsFromArgs(append([]string{"--agent", "fixer", "--workspace", workspaceRoot, "--dry-run"}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return run(cfg)
+	}
+
+	// Bugs come before chores at equal priority even though the chore is older
+	if result := claim(); result.Issue == nil || result.Issue.ID != "bug" {
+		t.Errorf("expected the bug first by default, got %+v", result)
+	}
+	result := claim("--type", "feature")
+	if result.Issue == nil || result.Issue.ID != "feature" {
+		t.Errorf("expected only the feature, got %+v", result)
+	}
+	if result.Filters == nil || !slices.Equal(result.Filters.IncludeTypes, []string{"feature"}) {
+		t.Errorf("expected the type filter echoed, got %+v", result.Filters)
+	}
+}
+
+func TestBuildFilters_Parent(t *testing.T) {
+	filters, err := buildFilters(config{parent: "bd-epic", directChildren: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filters.Parent != "bd-epic" || !filters.DirectChildren {
+		t.Errorf("expected direct children of bd-epic, got %+v", filters)
+	}
+
+	if _, err := buildFilters(config{directChildren: true}); err == nil {
+		t.Error("expected error for --direct-children without --parent")
+	}
+}
+
+func TestRun_Parent(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "epic", "Epic", 1)
+	insertIssue(t, workspaceRoot, "elsewhere", "Elsewhere", 0)
+	insertIssue(t, workspaceRoot, "story", "Story", 2)
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE dependencies (issue_id TEXT, depends_on_id TEXT, type TEXT);
+		INSERT INTO dependencies VALUES ('story', 'epic', 'parent-child');
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "epic-1", "--workspace", workspaceRoot, "--parent", "epic"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := run(cfg)
+	if result.Issue == nil || result.Issue.ID != "story" {
+		t.Fatalf("expected story, the epic's only descendant, got %+v", result)
+	}
+	if result.Filters == nil || result.Filters.Parent != "epic" {
+		t.Errorf("expected the parent in the filters, got %+v", result.Filters)
+	}
+}
+
+func TestBuildFilters_Strategy(t *testing.T) {
+	filters, err := buildFilters(config{strategy: "Critical-Path"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filters.Strategy != domain.StrategyCriticalPath {
+		t.Errorf("expected critical-path, got %q", filters.Strategy)
+	}
+
+	if _, err := buildFilters(config{strategy: "fastest"}); err == nil {
+		t.Error("expected error for an unknown strategy")
+	}
+}
+
+func TestRun_CriticalPath(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "urgent", "Urgent", 0)
+	insertIssue(t, workspaceRoot, "blocker", "Blocker", 3)
+	insertIssue(t, workspaceRoot, "waiting", "Waiting", 1)
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE dependencies (issue_id TEXT, depends_on_id TEXT, type TEXT);
+		INSERT INTO dependencies VALUES ('waiting', 'blocker', 'blocks');
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "path-1", "--workspace", workspaceRoot, "--strategy", "critical-path"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := run(cfg)
+	if result.Issue == nil || result.Issue.ID != "blocker" {
+		t.Fatalf("expected blocker, which unblocks waiting, got %+v", result)
+	}
+	if result.Issue.Unblocks == nil || *result.Issue.Unblocks != 1 {
+		t.Errorf("expected blocker to unblock 1 issue, got %v", result.Issue.Unblocks)
+	}
+	if result.Filters == nil || result.Filters.Strategy != "critical-path" {
+		t.Errorf("expected the strategy in the filters, got %+v", result.Filters)
+	}
+}
+
+func TestRun_InvalidPriority(t *testing.T) {
+	cfg := config{
+		agent:    "test-agent",
+		priority: "P9",
+	}
+
+	result := run(cfg)
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Error("expected INVALID_ARGUMENT error")
+	}
+}
+
+func TestRun_NegativeLease(t *testing.T) {
+	cfg := config{
+		agent: "test-agent",
+		lease: -time.Minute,
+	}
+
+	result := run(cfg)
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Error("expected INVALID_ARGUMENT error")
+	}
+}
+
+func TestRun_WithLease(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "test-agent", "--workspace", workspaceRoot, "--lease", "30m"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.lease != 30*time.Minute {
+		t.Fatalf("expected 30m lease, got %s", cfg.lease)
+	}
+
+	result := run(cfg)
+
+	if result.Status != "ok" || result.Issue == nil {
+		t.Fatalf("expected issue to be claimed, got %+v", result)
+	}
+	if result.Issue.LeaseExpiresAt == nil {
+		t.Error("expected lease_expires_at to be set")
+	}
+}
+
+func TestRun_Count(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-1", "First", 1)
+	insertIssue(t, workspaceRoot, "test-2", "Second", 2)
+	insertIssue(t, workspaceRoot, "test-3", "Third", 3)
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "test-agent", "--workspace", workspaceRoot, "--count", "2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := run(cfg)
+
+	if result.Status != "ok" {
+		t.Fatalf("expected status 'ok', got %+v", result)
+	}
+	if len(result.Issues) != 2 || result.Issues[0].ID != "test-1" || result.Issues[1].ID != "test-2" {
+		t.Errorf("expected test-1 and test-2, got %+v", result.Issues)
+	}
+	if result.Issue == nil || result.Issue.ID != "test-1" {
+		t.Errorf("expected issue to stay the first claimed issue, got %+v", result.Issue)
+	}
+}
+
+func TestRun_MaxInProgress(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-1", "First", 1)
+	insertIssue(t, workspaceRoot, "test-2", "Second", 2)
+	insertIssue(t, workspaceRoot, "test-3", "Third", 3)
+
+	// The workspace default applies unless the flag overrides it
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(args ...string) application.ClaimIssueResult {
+		cfg, err := parseFlagsFromArgs(append([]string{"--agent", "test-agent", "--workspace", workspaceRoot}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return run(cfg)
+	}
+
+	if result := claim(); result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-1" {
+		t.Fatalf("expected test-1 to be claimed, got %+v", result)
+	}
+
+	result := claim()
+	if result.Error == nil || result.Error.Code != "WIP_LIMIT_REACHED" {
+		t.Fatalf("expected WIP_LIMIT_REACHED error, got %+v", result.Error)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].ID != "test-1" {
+		t.Errorf("expected the agent's current issues, got %+v", result.Issues)
+	}
+
+	if result := claim("--max-in-progress", "2"); result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-2" {
+		t.Errorf("expected the flag to raise the limit, got %+v", result)
+	}
+	if result := claim("--max-in-progress", "0"); result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-3" {
+		t.Errorf("expected --max-in-progress 0 to lift the limit, got %+v", result)
+	}
+
+	if result := claim("--max-in-progress", "-2"); result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT for a negative limit, got %+v", result.Error)
+	}
+
+	// A broken config file is reported rather than ignored
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": "one"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := claim(); result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT for a bad config, got %+v", result.Error)
+	}
+}
+
+func TestRun_IssueByID(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-1", "First", 1)
+	insertIssue(t, workspaceRoot, "test-2", "Second", 3)
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "test-agent", "--workspace", workspaceRoot, "--issue", "test-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := run(cfg)
+	if result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-2" {
+		t.Fatalf("expected test-2 to be claimed, got %+v", result)
+	}
+
+	// A second claim of the same issue fails loudly instead of returning null
+	cfg.agent = "other-agent"
+	result = run(cfg)
+	if result.Error == nil || result.Error.Code != "ALREADY_CLAIMED" {
+		t.Errorf("expected ALREADY_CLAIMED error, got %+v", result.Error)
+	}
+
+	cfg.issueID = "missing"
+	result = run(cfg)
+	if result.Error == nil || result.Error.Code != "NOT_FOUND" {
+		t.Errorf("expected NOT_FOUND error, got %+v", result.Error)
+	}
+}
+
+func TestRun_WaitTimeout(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "test-agent", "--workspace", workspaceRoot, "--wait", "--wait-timeout", "50ms", "--poll-interval", "10ms"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := run(cfg)
+
+	if result.Status != "ok" || result.Issue != nil {
+		t.Fatalf("expected empty ok result, got %+v", result)
+	}
+	if result.Wait == nil || result.Wait.Outcome != "timeout" || result.Wait.WaitedMs < 40 {
+		t.Errorf("unexpected wait report: %+v", result.Wait)
+	}
+}
+
+func TestRun_WaitClaims(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	time.AfterFunc(30*time.Millisecond, func() {
+		insertIssue(t, workspaceRoot, "test-123", "Late Issue", 1)
+	})
+
+	result := run(config{
+		agent:        "test-agent",
+		workspace:    workspaceRoot,
+		timeoutMs:    1000,
+		wait:         true,
+		waitTimeout:  5 * time.Second,
+		pollInterval: 10 * time.Millisecond,
+	})
+
+	if result.Issue == nil || result.Issue.ID != "test-123" {
+		t.Fatalf("expected late issue to be claimed, got %+v", result)
+	}
+	if result.Wait == nil || result.Wait.Outcome != "claimed" || result.Wait.Attempts < 2 {
+		t.Errorf("unexpected wait report: %+v", result.Wait)
+	}
+}
+
+func TestRun_InvalidCount(t *testing.T) {
+	result := run(config{agent: "test-agent", count: -2})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Error("expected INVALID_ARGUMENT error")
+	}
+}
+
+func TestOutputHuman_Batch(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	first := &application.IssueDTO{ID: "test-1", Title: "First", Status: "in_progress"}
+	second := &application.IssueDTO{ID: "test-2", Title: "Second", Status: "in_progress"}
+	result := application.ClaimIssueResult{
+		Status: "ok",
+		Agent:  "test-agent",
+		Issue:  first,
+		Issues: []*application.IssueDTO{first, second},
+	}
+
+	if code := outputHuman(result); code != 0 {
+		t.Errorf("expected exit code 0, got %d", code)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Claimed issue test-1: First") || !strings.Contains(out, "Claimed issue test-2: Second") {
+		t.Errorf("expected both issues in output, got %q", out)
+	}
+}
+
 func TestRun_InvalidDbPath(t *testing.T) {
 	cfg := config{
 		agent:  "test-agent",
@@ -597,6 +1334,9 @@ func TestOutputHuman_Success(t *testing.T) {
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
+	if !bytes.Contains(buf.Bytes(), []byte("Priority: P1 (high)")) {
+		t.Errorf("expected P1 priority name in output, got %q", buf.String())
+	}
 }
 
 func TestOutputHuman_NoIssue(t *testing.T) {
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// mcpProtocolVersions lists the MCP revisions bd-claim speaks, newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes used by the MCP server.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool describes a tool in tools/list.
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// mcpToolResult is the result of tools/call. Claim results travel both as
+// text and as structured content.
+type mcpToolResult struct {
+	Content           []mcpContent                 `json:"content"`
+	StructuredContent application.ClaimIssueResult `json:"structuredContent"`
+	IsError           bool                         `json:"isError"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// filterSchema mirrors FiltersDTO in tool input schemas.
+var filterSchema = map[string]interface{}{
+	"agent": map[string]interface{}{
+		"type":        "string",
+		"description": "Agent name: letters, digits, '-' and '_', at most 64 characters",
+	},
+	"labels": map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Only issues carrying all of these labels",
+	},
+	"exclude_labels": map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Skip issues carrying any of these labels",
+	},
+	"types": map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Only issues of these issue types, e.g. bug",
+	},
+	"exclude_types": map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Skip issues of these issue types",
+	},
+	"parent": map[string]interface{}{
+		"type":        "string",
+		"description": "Only descendants of this issue through parent-child dependencies, e.g. an epic",
+	},
+	"direct_children": map[string]interface{}{
+		"type":        "boolean",
+		"description": "With parent, only its immediate children",
+	},
+	"where": map[string]interface{}{
+		"type":        "string",
+		"description": "Label expression the issue must match, e.g. label:frontend or label:docs and not label:blocked-external",
+	},
+	"priority": map[string]interface{}{
+		"type":        "string",
+		"description": "Only this priority or inclusive range, e.g. P1 or 0-2",
+	},
+	"max_priority": map[string]interface{}{
+		"type":        "string",
+		"description": "Least urgent priority to consider, P0 (critical) to P4 (backlog)",
+	},
+	"only_unassigned": map[string]interface{}{
+		"type":        "boolean",
+		"description": "Only issues with no assignee",
+	},
+	"readiness": map[string]interface{}{
+		"type":        "string",
+		"enum":        []string{"auto", "cache", "dependencies"},
+		"description": "How to decide an issue is unblocked",
+	},
+	"strategy": map[string]interface{}{
+		"type":        "string",
+		"enum":        []string{"priority", "critical-path"},
+		"description": "Claim order: priority, or critical-path to claim issues that unblock the most work first",
+	},
+	"label_limits": map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "integer", "minimum": 0},
+		"description":          "Skip issues whose label already has this many issues in progress across all agents, e.g. {\"release\": 1}; 0 lifts a configured limit",
+	},
+	"prefer_labels": map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Among issues of equal priority, claim those carrying these labels first",
+	},
+	"exclusive_prefixes": map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Label prefixes such as component: whose labels mark mutually exclusive work; skip issues sharing such a label with an issue in progress",
+	},
+}
+
+// withProperties returns an object schema with the filter properties plus extra.
+func withProperties(extra map[string]interface{}, required ...string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for name, schema := range filterSchema {
+		properties[name] = schema
+	}
+	for name, schema := range extra {
+		properties[name] = schema
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "claim_issue",
+		Description: "Atomically claim the next ready Beads issue (or a given one) for an agent. Returns the claimed issue, or a null issue when nothing is ready.",
+		InputSchema: withProperties(map[string]interface{}{
+			"lease":           map[string]interface{}{"type": "string", "description": "Hold the claim this long, e.g. 30m; afterwards others may reclaim it"},
+			"max_in_progress": map[string]interface{}{"type": "integer", "minimum": 0, "description": "Refuse to claim once the agent holds this many in-progress issues; 0 means no limit"},
+			"count":           map[string]interface{}{"type": "integer", "minimum": 1, "description": "Claim up to this many issues at once"},
+			"issue":           map[string]interface{}{"type": "string", "description": "Claim this issue only"},
+			"wait":            map[string]interface{}{"type": "boolean", "description": "Keep polling until an issue is claimed"},
+			"wait_timeout":    map[string]interface{}{"type": "string", "description": "Give up waiting after this long, e.g. 10m"},
+			"poll_interval":   map[string]interface{}{"type": "string", "description": "Average delay between attempts while waiting"},
+			"dry_run":         map[string]interface{}{"type": "boolean", "description": "Show what would be claimed without claiming"},
+		}, "agent"),
+	},
+	{
+		Name:        "peek_ready",
+		Description: "List the ready queue in claim order without claiming anything.",
+		InputSchema: withProperties(map[string]interface{}{
+			"limit": map[string]interface{}{"type": "integer", "minimum": 1, "description": "Most issues to list (default 10)"},
+		}, "agent"),
+	},
+	{
+		Name:        "list_my_issues",
+		Description: "List the issues an agent currently has in progress.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"agent": filterSchema["agent"],
+			},
+			"required":             []string{"agent"},
+			"additionalProperties": false,
+		},
+	},
+}
+
+// runMCPApp is the entrypoint for `bd-claim mcp`.
+func runMCPApp(args []string) int {
+	cfg, err := parseMCPFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serveMCP(ctx, cfg, os.Stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "Error: %s\n", err.Error())
+		return 1
+	}
+	return 0
+}
+
+func parseMCPFlags(args []string) (config, error) {
+	cfg := config{command: "mcp"}
+	fs := flag.NewFlagSet("bd-claim mcp", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	fs.StringVar(&cfg.workspace, "workspace", "", "Override workspace root path")
+	fs.StringVar(&cfg.dbPath, "db", "", "Override database path")
+	fs.IntVar(&cfg.timeoutMs, "timeout-ms", 3000, "Database busy timeout in milliseconds")
+	fs.StringVar(&cfg.logLevel, "log-level", "error", "Log level (debug, info, warn, error)")
+	fs.BoolVar(&cfg.skipVersionCheck, "skip-version-check", false, "Skip database version compatibility check")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+// mcpServer answers MCP requests against an open repository.
+type mcpServer struct {
+	repo    application.IssueRepositoryPort
+	configs *infrastructure.ClaimConfigLoader
+	logger  application.LoggerPort
+}
+
+// serveMCP speaks MCP over newline-delimited JSON-RPC on in and out until in
+// is closed or ctx is cancelled. Requests are handled one at a time.
+func serveMCP(ctx context.Context, cfg config, in io.Reader, out io.Writer) error {
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	dbPath, err := resolveDbPath(cfg, logger)
+	if err != nil {
+		return err
+	}
+	repo, configs, err := openServerRepository(dbPath, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	server := &mcpServer{repo: repo, configs: configs, logger: logger}
+	encoder := json.NewEncoder(out)
+	reader := bufio.NewReader(in)
+
+	for ctx.Err() == nil {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := server.handle(ctx, line); resp != nil {
+				if err := encoder.Encode(resp); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handle answers one JSON-RPC message. Notifications get no response.
+func (s *mcpServer) handle(ctx context.Context, line []byte) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return &rpcResponse{JSONRPC: "2.0", ID: idOrNull(req.ID), Error: &rpcError{Code: rpcInvalidRequest, Message: "not a JSON-RPC 2.0 request"}}
+	}
+
+	result, rpcErr := s.dispatch(ctx, req)
+	if req.ID == nil {
+		return nil
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+}
+
+func (s *mcpServer) dispatch(ctx context.Context, req rpcRequest) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		return map[string]interface{}{
+			"protocolVersion": negotiateProtocolVersion(params.ProtocolVersion),
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "bd-claim", "version": Version},
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": mcpTools}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	}
+
+	// Notifications such as notifications/initialized need no handling
+	if req.ID == nil {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// callTool runs a tool. Claim failures are tool results with isError set;
+// only malformed calls are JSON-RPC errors.
+func (s *mcpServer) callTool(ctx context.Context, raw json.RawMessage) (interface{}, *rpcError) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	if len(call.Arguments) == 0 {
+		call.Arguments = json.RawMessage("{}")
+	}
+
+	var result application.ClaimIssueResult
+	switch call.Name {
+	case "claim_issue":
+		var params claimParams
+		if err := decodeArguments(call.Arguments, &params); err != nil {
+			return nil, err
+		}
+		result = s.claim(ctx, params.Agent, params.config)
+	case "peek_ready":
+		var params readyParams
+		if err := decodeArguments(call.Arguments, &params); err != nil {
+			return nil, err
+		}
+		result = s.claim(ctx, params.Agent, params.config)
+	case "list_my_issues":
+		var params struct {
+			Agent string `json:"agent"`
+		}
+		if err := decodeArguments(call.Arguments, &params); err != nil {
+			return nil, err
+		}
+		result = s.listIssues(ctx, params.Agent)
+	default:
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "unknown tool: " + call.Name}
+	}
+
+	text, _ := json.Marshal(result)
+	return mcpToolResult{
+		Content:           []mcpContent{{Type: "text", Text: string(text)}},
+		StructuredContent: result,
+		IsError:           result.Status == "error",
+	}, nil
+}
+
+// claim validates tool parameters like the CLI validates flags and runs the claim.
+func (s *mcpServer) claim(ctx context.Context, agent string, toConfig func() (config, error)) application.ClaimIssueResult {
+	cfg, err := toConfig()
+	if err != nil {
+		return handleDomainError(agent, err)
+	}
+	cfg, err = applyCurrentClaimConfig(cfg, s.configs)
+	if err != nil {
+		return handleDomainError(agent, err)
+	}
+	req, err := buildClaimRequest(cfg)
+	if err != nil {
+		return handleDomainError(agent, err)
+	}
+	return executeClaim(ctx, cfg, req, s.repo, s.logger)
+}
+
+func (s *mcpServer) listIssues(ctx context.Context, name string) application.ClaimIssueResult {
+	agent, err := parseAgent(name)
+	if err != nil {
+		return handleDomainError(name, err)
+	}
+	useCase := application.NewListAgentIssuesUseCase(s.repo, s.logger)
+	return useCase.Execute(ctx, application.ListAgentIssuesRequest{Agent: agent})
+}
+
+func decodeArguments(raw json.RawMessage, v interface{}) *rpcError {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: "invalid arguments: " + err.Error()}
+	}
+	return nil
+}
+
+// negotiateProtocolVersion echoes the client's MCP revision when supported
+// and offers the newest one otherwise.
+func negotiateProtocolVersion(requested string) string {
+	for _, version := range mcpProtocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return mcpProtocolVersions[0]
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if id == nil {
+		return json.RawMessage("null")
+	}
+	return id
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// mcpSession sends lines to a fresh MCP server and returns its responses.
+func mcpSession(t *testing.T, workspace string, lines ...string) []rpcResponse {
+	t.Helper()
+
+	cfg, err := parseMCPFlags([]string{"--workspace", workspace})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(lines, "\n") + "\n")
+	if err := serveMCP(context.Background(), cfg, in, &out); err != nil {
+		t.Fatalf("serveMCP returned error: %v", err)
+	}
+
+	var responses []rpcResponse
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp rpcResponse
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// toolResult decodes the structured content of a tools/call response.
+func toolResult(t *testing.T, resp rpcResponse) mcpToolResult {
+	t.Helper()
+
+	if resp.Error != nil {
+		t.Fatalf("unexpected JSON-RPC error: %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var result mcpToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestMCP_Handshake(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	responses := mcpSession(t, tmpDir,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+	)
+
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 responses (no reply to the notification), got %d", len(responses))
+	}
+
+	init := responses[0].Result.(map[string]interface{})
+	if init["protocolVersion"] != "2025-03-26" {
+		t.Errorf("expected the client's protocol version, got %v", init["protocolVersion"])
+	}
+
+	tools := responses[1].Result.(map[string]interface{})["tools"].([]interface{})
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "claim_issue,peek_ready,list_my_issues" {
+		t.Errorf("unexpected tools: %v", names)
+	}
+
+	if responses[2].Error == nil || responses[2].Error.Code != rpcMethodNotFound {
+		t.Errorf("expected method not found, got %+v", responses[2])
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != rpcParseError {
+		t.Errorf("expected parse error, got %+v", responses[3])
+	}
+}
+
+func TestMCP_Tools(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+	insertIssue(t, tmpDir, "test-2", "Second", 2)
+
+	responses := mcpSession(t, tmpDir,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"peek_ready","arguments":{"agent":"agent-a"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"claim_issue","arguments":{"agent":"agent-a","lease":"5m"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"list_my_issues","arguments":{"agent":"agent-a"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"claim_issue","arguments":{"agent":"bad agent!"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"claim_issue","arguments":{"agent":"a","colour":"red"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"delete_everything","arguments":{}}}`,
+	)
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses, got %d", len(responses))
+	}
+
+	peek := toolResult(t, responses[0])
+	if peek.IsError || len(peek.StructuredContent.Issues) != 2 {
+		t.Errorf("expected both issues to be ready, got %+v", peek.StructuredContent)
+	}
+
+	claim := toolResult(t, responses[1])
+	if claim.IsError || claim.StructuredContent.Issue == nil || claim.StructuredContent.Issue.ID != "test-1" {
+		t.Fatalf("expected test-1 to be claimed, got %+v", claim.StructuredContent)
+	}
+	if len(claim.Content) != 1 || !strings.Contains(claim.Content[0].Text, `"test-1"`) {
+		t.Errorf("expected the result as text content, got %+v", claim.Content)
+	}
+
+	mine := toolResult(t, responses[2])
+	if len(mine.StructuredContent.Issues) != 1 || mine.StructuredContent.Issues[0].ID != "test-1" {
+		t.Errorf("expected agent-a to hold test-1, got %+v", mine.StructuredContent)
+	}
+
+	invalid := toolResult(t, responses[3])
+	if !invalid.IsError || invalid.StructuredContent.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT tool error, got %+v", invalid)
+	}
+
+	for _, resp := range responses[4:] {
+		if resp.Error == nil || resp.Error.Code != rpcInvalidParams {
+			t.Errorf("expected invalid params, got %+v", resp)
+		}
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	if got := negotiateProtocolVersion("2024-11-05"); got != "2024-11-05" {
+		t.Errorf("expected supported version to be echoed, got %s", got)
+	}
+	if got := negotiateProtocolVersion("1999-01-01"); got != mcpProtocolVersions[0] {
+		t.Errorf("expected newest version, got %s", got)
+	}
+}
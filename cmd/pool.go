@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// runPoolApp is the entrypoint for `bd-claim pool`.
+func runPoolApp(args []string) int {
+	cfg, err := parsePoolFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := runPool(cfg, signals); err != nil {
+		fmt.Fprintf(stderr, "Error: %s\n", err.Error())
+		return 1
+	}
+	return 0
+}
+
+// parsePoolFlags parses `[pool flags] -- command [args...]`.
+func parsePoolFlags(args []string) (config, error) {
+	flags, command, err := splitCommand("pool", args)
+	if err != nil {
+		return config{}, err
+	}
+
+	cfg := config{command: "pool", exec: command, count: 1, wait: true, maxInProgress: unsetMaxInProgress}
+	fs := flag.NewFlagSet("bd-claim pool", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	registerCommonFlags(fs, &cfg)
+	registerFilterFlags(fs, &cfg)
+	fs.IntVar(&cfg.poolSize, "size", 1, "Number of concurrent worker slots")
+	fs.StringVar(&cfg.agentPrefix, "agent-prefix", "worker", "Slot N claims as agent <prefix>-N")
+	fs.DurationVar(&cfg.lease, "lease", 0, "Claim lease (e.g. 30m); renewed while the command runs")
+	fs.DurationVar(&cfg.pollInterval, "poll-interval", 2*time.Second, "Average delay between claim attempts while a slot is idle (jittered)")
+
+	if err := fs.Parse(flags); err != nil {
+		return config{}, err
+	}
+	if cfg.agent != "" {
+		return config{}, fmt.Errorf("--agent cannot be used with pool; slots are named by --agent-prefix")
+	}
+
+	return cfg, nil
+}
+
+// pool keeps a fixed number of slots busy, each claiming an issue as its own
+// agent and running the command on it.
+type pool struct {
+	cfg    config
+	repo   application.IssueRepositoryPort
+	logger application.LoggerPort
+
+	// mu serialises result output from concurrent slots.
+	mu sync.Mutex
+}
+
+// runPool runs cfg.poolSize slots until the first signal arrives. The signal
+// stops new claims and is forwarded to running commands; their claims are
+// released unless the command still exits 0. A slot that cannot claim, because
+// its claim is invalid or fails with an error waiting would not retry, stops
+// the pool as a SIGTERM would, and its error is returned.
+func runPool(cfg config, signals <-chan os.Signal) error {
+	if cfg.poolSize < 1 {
+		return fmt.Errorf("--size must be at least 1")
+	}
+	agents := make([]domain.AgentName, cfg.poolSize)
+	for i := range agents {
+		agent, err := domain.NewAgentName(fmt.Sprintf("%s-%d", cfg.agentPrefix, i+1))
+		if err != nil {
+			return fmt.Errorf("invalid --agent-prefix: %w", err)
+		}
+		agents[i] = agent
+	}
+
+	// Reject bad filters once instead of in every slot
+	cfg.agent = agents[0].String()
+	if _, err := buildClaimRequest(cfg); err != nil {
+		return err
+	}
+
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+	repo, claimConfig, err := openClaimRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	cfg = applyClaimConfig(cfg, claimConfig)
+
+	p := &pool{cfg: cfg, repo: repo, logger: logger}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slots := make([]chan os.Signal, len(agents))
+	var wg sync.WaitGroup
+	var slotErr error
+	var slotErrOnce sync.Once
+	failed := make(chan struct{})
+	for i, agent := range agents {
+		slots[i] = make(chan os.Signal, 1)
+		wg.Add(1)
+		go func(slot int, agent domain.AgentName, signals <-chan os.Signal) {
+			defer wg.Done()
+			if err := p.runSlot(ctx, slot, agent, signals); err != nil {
+				logger.Error("slot_failed", map[string]interface{}{
+					"agent": agent.String(),
+					"error": err.Error(),
+				})
+				slotErrOnce.Do(func() {
+					slotErr = fmt.Errorf("slot %d (%s): %w", slot, agent, err)
+					close(failed)
+				})
+			}
+		}(i+1, agent, slots[i])
+	}
+
+	logger.Info("pool_started", map[string]interface{}{
+		"size":   len(agents),
+		"prefix": cfg.agentPrefix,
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	stop := func(sig os.Signal) {
+		cancel()
+		for _, slot := range slots {
+			select {
+			case slot <- sig:
+			default:
+			}
+		}
+	}
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("pool_stopping", map[string]interface{}{
+				"signal": sig.String(),
+			})
+			stop(sig)
+		case <-failed:
+			// Stop the other slots' commands as a SIGTERM would
+			logger.Info("pool_stopping", map[string]interface{}{
+				"signal": syscall.SIGTERM.String(),
+				"reason": "slot_failed",
+			})
+			stop(syscall.SIGTERM)
+			failed = nil
+		case <-finished:
+			logger.Info("pool_stopped", map[string]interface{}{
+				"size": len(agents),
+			})
+			return slotErr
+		}
+	}
+}
+
+// runSlot claims and works issues as agent until ctx is cancelled. It fails if
+// the agent's claim cannot be built, e.g. from its profile, or if a claim fails
+// with an error that waiting does not retry, such as SCHEMA_INCOMPATIBLE.
+func (p *pool) runSlot(ctx context.Context, slot int, agent domain.AgentName, signals <-chan os.Signal) error {
+	cfg := p.cfg
+	cfg.agent = agent.String()
+	req, err := buildClaimRequest(cfg)
+	if err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		claimed := executeClaim(ctx, cfg, req, p.repo, p.logger)
+		if claimed.Status == "error" {
+			if !application.RetryableWhileWaiting(claimed.Error.Code) {
+				return fmt.Errorf("%s: %s", claimed.Error.Code, claimed.Error.Message)
+			}
+			p.logger.Warn("slot_claim_failed", map[string]interface{}{
+				"agent": cfg.agent,
+				"code":  claimed.Error.Code,
+				"error": claimed.Error.Message,
+			})
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.pollInterval):
+			}
+			continue
+		}
+		if claimed.Issue == nil {
+			continue
+		}
+
+		exitCode, outcome, leaseLost := runClaimedCommand(cfg, claimed, p.repo, p.logger, signals, []string{
+			"BD_CLAIM_SLOT=" + strconv.Itoa(slot),
+		})
+		p.report(cfg, finishClaim(claimed, exitCode, outcome, leaseLost, p.repo, p.logger))
+	}
+	return nil
+}
+
+// report prints a finished issue's close or release result.
+func (p *pool) report(cfg config, result application.ClaimIssueResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	outputResult(cfg, result)
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParsePoolFlags(t *testing.T) {
+	cfg, err := parsePoolFlags([]string{"--size", "4", "--agent-prefix", "builder", "--label", "backend", "--", "./work.sh"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "pool" || cfg.poolSize != 4 || cfg.agentPrefix != "builder" || len(cfg.labels) != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if !cfg.wait || cfg.count != 1 || strings.Join(cfg.exec, " ") != "./work.sh" {
+		t.Errorf("expected slots to wait for one issue at a time, got %+v", cfg)
+	}
+
+	for _, args := range [][]string{
+		{"--size", "2"},
+		{"--agent", "a", "--", "true"},
+		{"--count", "2", "--", "true"},
+	} {
+		if _, err := parsePoolFlags(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestRunPool_InvalidConfig(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"--size", "0", "--workspace", workspaceRoot, "--", "true"},
+		{"--agent-prefix", "bad prefix!", "--workspace", workspaceRoot, "--", "true"},
+		{"--priority", "urgent", "--workspace", workspaceRoot, "--", "true"},
+	} {
+		cfg, err := parsePoolFlags(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := runPool(cfg, make(chan os.Signal)); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestRunPool_SlotFailureStopsPool(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// The profile only breaks the second slot's filters
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"profiles": {"worker-2": {"types": [""]}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := parsePoolFlags([]string{"--size", "2", "--workspace", workspaceRoot, "--poll-interval", "10ms", "--", "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- runPool(cfg, make(chan os.Signal)) }()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "worker-2") {
+			t.Errorf("expected the failing slot's error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("pool kept running without its failed slot")
+	}
+}
+
+func TestRunPool_ClaimErrorStopsPool(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-1", "First", 1)
+	insertIssue(t, workspaceRoot, "held", "Held", 1)
+
+	// worker-1 already holds its one issue, so every claim would fail alike
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`UPDATE issues SET status = 'in_progress', assignee = 'worker-1' WHERE id = 'held'`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := parsePoolFlags([]string{"--size", "1", "--workspace", workspaceRoot, "--poll-interval", "10ms", "--", "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- runPool(cfg, make(chan os.Signal)) }()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "WIP_LIMIT_REACHED") {
+			t.Errorf("expected the slot's claim error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("pool kept retrying a claim that cannot succeed")
+	}
+}
+
+func TestRunPool_SlotFailureStopsRunningCommands(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-1", "Long job", 1)
+	configPath := filepath.Join(workspaceRoot, ".beads", "bd-claim.json")
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf syncBuffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	ready := filepath.Join(t.TempDir(), "ready")
+	cfg, err := parsePoolFlags([]string{"--size", "2", "--workspace", workspaceRoot, "--poll-interval", "20ms", "--",
+		"sh", "-c", `trap 'exit 9' TERM; touch ` + ready + `; while true; do sleep 0.05; done`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- runPool(cfg, make(chan os.Signal)) }()
+
+	waitFor(t, "the command to start", func() bool {
+		_, err := os.Stat(ready)
+		return err == nil
+	})
+
+	// Put the idle slot at its WIP limit, so its next claim fails for good
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO issues (id, title, status, assignee, priority, created_at, updated_at)
+		SELECT 'held', 'Held', 'in_progress', CASE assignee WHEN 'worker-1' THEN 'worker-2' ELSE 'worker-1' END, 1,
+			datetime('now'), datetime('now')
+		FROM issues WHERE id = 'test-1'`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "WIP_LIMIT_REACHED") {
+			t.Errorf("expected the failing slot's error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool left the running command alone")
+	}
+
+	if n := countIssuesWithStatus(t, workspaceRoot, "open"); n != 1 {
+		t.Errorf("expected the interrupted claim to be released, got %d open issues", n)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of pool slots.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// countIssuesWithStatus counts issues in a test workspace by status.
+func countIssuesWithStatus(t *testing.T, workspaceRoot, status string) int {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM issues WHERE status = ?`, status).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRunPool_WorksAllIssues(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	for _, id := range []string{"test-1", "test-2", "test-3", "test-4", "test-5"} {
+		insertIssue(t, workspaceRoot, id, "Issue "+id, 1)
+	}
+
+	var buf syncBuffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	logDir := t.TempDir()
+	cfg, err := parsePoolFlags([]string{"--size", "3", "--workspace", workspaceRoot, "--poll-interval", "20ms", "--",
+		"sh", "-c", `echo "$BD_CLAIM_SLOT" > ` + logDir + `/"$BD_CLAIM_AGENT-$BD_CLAIM_ISSUE_ID"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() { done <- runPool(cfg, signals) }()
+
+	waitFor(t, "every issue to close", func() bool {
+		return countIssuesWithStatus(t, workspaceRoot, "closed") == 5
+	})
+
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool did not stop")
+	}
+
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected each issue to run once, got %d runs", len(entries))
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "worker-") {
+			t.Errorf("expected a derived worker agent, got %s", entry.Name())
+		}
+	}
+	if n := strings.Count(buf.String(), `"status":"closed"`); n != 5 {
+		t.Errorf("expected 5 close results, got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestRunPool_ShutdownReleasesClaims(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-1", "Long job", 1)
+
+	var buf syncBuffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	ready := filepath.Join(t.TempDir(), "ready")
+	cfg, err := parsePoolFlags([]string{"--size", "2", "--workspace", workspaceRoot, "--poll-interval", "20ms", "--",
+		"sh", "-c", `trap 'exit 9' TERM; touch ` + ready + `; while true; do sleep 0.05; done`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() { done <- runPool(cfg, signals) }()
+
+	waitFor(t, "the command to start", func() bool {
+		_, err := os.Stat(ready)
+		return err == nil
+	})
+
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool did not stop")
+	}
+
+	if n := countIssuesWithStatus(t, workspaceRoot, "open"); n != 1 {
+		t.Errorf("expected the unfinished claim to be released, got %d open issues", n)
+	}
+}
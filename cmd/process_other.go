@@ -0,0 +1,17 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup leaves cmd in bd-claim's process group, with its stdin,
+// where there are no Unix process groups.
+func setProcessGroup(cmd *exec.Cmd) bool { return false }
+
+// signalCommand sends sig to cmd.
+func signalCommand(cmd *exec.Cmd, sig os.Signal) error {
+	return cmd.Process.Signal(sig)
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a process group of its own, so a signal the
+// terminal sends to bd-claim's group reaches the command once, through
+// signalCommand, rather than twice. A background group is stopped by SIGTTIN
+// when it reads the terminal, so a terminal on stdin is not handed to the
+// command; setProcessGroup reports whether it took one away.
+func setProcessGroup(cmd *exec.Cmd) bool {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	f, ok := cmd.Stdin.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null, the usual stdin of a detached run, never stops a reader
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	cmd.Stdin = nil
+	return true
+}
+
+// signalCommand sends sig to the process group setProcessGroup started cmd
+// in, reaching any processes the command started as well.
+func signalCommand(cmd *exec.Cmd, sig os.Signal) error {
+	if s, ok := sig.(syscall.Signal); ok {
+		return syscall.Kill(-cmd.Process.Pid, s)
+	}
+	return cmd.Process.Signal(sig)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// runReleaseApp is the entrypoint for `bd-claim release`.
+func runReleaseApp(args []string) int {
+	cfg, err := parseReleaseFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	result := runRelease(cfg)
+	return outputResult(cfg, result)
+}
+
+func parseReleaseFlags(args []string) (config, error) {
+	cfg := config{command: "release"}
+	fs := flag.NewFlagSet("bd-claim release", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	registerCommonFlags(fs, &cfg)
+	fs.StringVar(&cfg.issueID, "issue", "", "ID of the claimed issue to release (required)")
+	fs.StringVar(&cfg.reason, "reason", "", "Why the issue is being released (recorded as a comment)")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+func runRelease(cfg config) application.ClaimIssueResult {
+	agent, err := parseAgent(cfg.agent)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	if cfg.issueID == "" {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--issue flag is required")
+	}
+
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	defer repo.Close()
+
+	clock := infrastructure.NewSystemClock()
+	useCase := application.NewReleaseIssueUseCase(repo, clock, logger)
+
+	req := application.ReleaseIssueRequest{
+		Agent:   agent,
+		IssueID: domain.IssueId(cfg.issueID),
+		Reason:  cfg.reason,
+	}
+
+	return useCase.Execute(context.Background(), req)
+}
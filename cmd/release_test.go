@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/application"
+)
+
+func TestParseReleaseFlags(t *testing.T) {
+	cfg, err := parseReleaseFlags([]string{"--agent", "test-agent", "--issue", "bd-1", "--reason", "stuck", "--human"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "release" {
+		t.Errorf("expected command 'release', got '%s'", cfg.command)
+	}
+	if cfg.agent != "test-agent" || cfg.issueID != "bd-1" || cfg.reason != "stuck" || !cfg.human {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	if _, err := parseReleaseFlags([]string{"--dry-run"}); err == nil {
+		t.Error("expected error for claim-only flag")
+	}
+}
+
+func TestRunRelease_MissingIssue(t *testing.T) {
+	result := runRelease(config{agent: "test-agent"})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestRunRelease_MissingAgent(t *testing.T) {
+	result := runRelease(config{issueID: "bd-1"})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestRunRelease_NoWorkspace(t *testing.T) {
+	result := runRelease(config{agent: "test-agent", issueID: "bd-1", workspace: t.TempDir()})
+
+	if result.Error == nil || result.Error.Code != "WORKSPACE_NOT_FOUND" {
+		t.Errorf("expected WORKSPACE_NOT_FOUND error, got %+v", result.Error)
+	}
+}
+
+func TestRunApp_ClaimThenRelease(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	if code := runApp([]string{"--agent", "test-agent", "--workspace", workspaceRoot}); code != 0 {
+		t.Fatalf("claim failed with exit code %d: %s", code, buf.String())
+	}
+	buf.Reset()
+
+	// Another agent cannot release it
+	code := runApp([]string{"release", "--agent", "other-agent", "--issue", "test-123", "--workspace", workspaceRoot})
+	if code != 1 {
+		t.Errorf("expected exit code 1 for non-owner, got %d", code)
+	}
+	var result application.ClaimIssueResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if result.Error == nil || result.Error.Code != "NOT_OWNER" {
+		t.Errorf("expected NOT_OWNER error, got %+v", result.Error)
+	}
+	buf.Reset()
+
+	code = runApp([]string{"release", "--agent", "test-agent", "--issue", "test-123", "--workspace", workspaceRoot, "--human"})
+	if code != 0 {
+		t.Fatalf("release failed with exit code %d: %s", code, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Released issue test-123") {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestRunApp_ReleaseInvalidFlag(t *testing.T) {
+	var buf bytes.Buffer
+	oldStderr := stderr
+	stderr = &buf
+	defer func() { stderr = oldStderr }()
+
+	if code := runApp([]string{"release", "--bogus"}); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// socketName is the server socket created next to the Beads database.
+const socketName = "bd-claim.sock"
+
+// serverDialTimeout bounds how long a client waits to reach the server
+// before falling back to the database.
+const serverDialTimeout = 500 * time.Millisecond
+
+// serverRequest is the single JSON line a client sends: the arguments of the
+// claim command it would otherwise run itself, and the absolute path of the
+// database those arguments resolved to. The server answers with one
+// ClaimIssueResult line.
+type serverRequest struct {
+	Args   []string `json:"args"`
+	DbPath string   `json:"db_path,omitempty"`
+}
+
+// runServeApp is the entrypoint for `bd-claim serve`.
+func runServeApp(args []string) int {
+	cfg, err := parseServeFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serve(ctx, cfg); err != nil {
+		fmt.Fprintf(stderr, "Error: %s\n", err.Error())
+		return 1
+	}
+	return 0
+}
+
+func parseServeFlags(args []string) (config, error) {
+	cfg := config{command: "serve"}
+	fs := flag.NewFlagSet("bd-claim serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	fs.StringVar(&cfg.workspace, "workspace", "", "Override workspace root path")
+	fs.StringVar(&cfg.dbPath, "db", "", "Override database path")
+	fs.StringVar(&cfg.socket, "socket", "", "Socket path (default: bd-claim.sock next to the database)")
+	fs.StringVar(&cfg.httpAddr, "http", "", "Also serve the HTTP/JSON API on this address (e.g. 127.0.0.1:7878)")
+	fs.IntVar(&cfg.timeoutMs, "timeout-ms", 3000, "Database busy timeout in milliseconds")
+	fs.StringVar(&cfg.logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	fs.BoolVar(&cfg.skipVersionCheck, "skip-version-check", false, "Skip database version compatibility check")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+// serve holds the database open and answers claims on a Unix socket, and
+// over HTTP when cfg.httpAddr is set, until ctx is cancelled. In-flight waits
+// are interrupted on shutdown.
+func serve(ctx context.Context, cfg config) error {
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	// Resolved once, so the database served is the one claims are checked against
+	dbPath, err := resolveDbPath(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	repo, configs, err := openServerRepository(dbPath, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	socket := cfg.socket
+	if socket == "" {
+		socket = socketPath(dbPath)
+	}
+
+	listener, err := listenUnix(socket)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	logger.Info("server_listening", map[string]interface{}{
+		"socket":  socket,
+		"db_path": dbPath,
+	})
+
+	// A failing HTTP server stops the Unix socket too
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	httpErr := make(chan error, 1)
+
+	if cfg.httpAddr != "" {
+		token, tokenSource, err := loadHTTPToken(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to load HTTP token: %w", err)
+		}
+
+		httpListener, err := net.Listen("tcp", cfg.httpAddr)
+		if err != nil {
+			return err
+		}
+		server := &http.Server{
+			Handler:           newHTTPHandler(repo, configs, token, logger),
+			BaseContext:       func(net.Listener) context.Context { return ctx },
+			ReadHeaderTimeout: httpReadHeaderTimeout,
+			ReadTimeout:       httpReadTimeout,
+			IdleTimeout:       httpIdleTimeout,
+		}
+		defer server.Shutdown(context.Background())
+		go func() {
+			if err := server.Serve(httpListener); !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("http_serve_failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+				httpErr <- err
+				cancel()
+			}
+		}()
+
+		logger.Info("http_listening", map[string]interface{}{
+			"addr":  httpListener.Addr().String(),
+			"token": tokenSource,
+		})
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handleServerConn(ctx, conn, dbPath, repo, configs, logger)
+		}()
+	}
+
+	wg.Wait()
+	logger.Info("server_stopped", map[string]interface{}{
+		"socket": socket,
+	})
+
+	select {
+	case err := <-httpErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// socketPath returns where the server for a database listens.
+func socketPath(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), socketName)
+}
+
+// listenUnix listens on socket, replacing a stale socket file left behind by
+// a server that did not shut down cleanly.
+func listenUnix(socket string) (net.Listener, error) {
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.DialTimeout("unix", socket, serverDialTimeout); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a server is already listening on %s", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// Only the owner of the workspace may claim through the server. The
+	// socket is created private rather than narrowed afterwards, so no other
+	// user can connect in between.
+	listener, err := listenPrivate(socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// handleServerConn answers one claim request. A client that disconnects
+// while waiting interrupts its wait.
+func handleServerConn(
+	ctx context.Context,
+	conn net.Conn,
+	dbPath string,
+	repo application.IssueRepositoryPort,
+	configs *infrastructure.ClaimConfigLoader,
+	logger application.LoggerPort,
+) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+		logger.Warn("server_read_failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// The client sends nothing after its request, so any read returning
+		// means it hung up
+		io.Copy(io.Discard, reader)
+		cancel()
+	}()
+
+	var result application.ClaimIssueResult
+	var req serverRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		result = errorResult("", domain.ErrCodeInvalidArgument, "invalid request: "+err.Error())
+	} else if req.DbPath != "" && !sameFile(req.DbPath, dbPath) {
+		result = errorResult("", domain.ErrCodeInvalidArgument, fmt.Sprintf(
+			"the server on this socket serves %s, not %s", dbPath, req.DbPath))
+	} else {
+		result = serveClaim(ctx, req.Args, repo, configs, logger)
+	}
+
+	if err := json.NewEncoder(conn).Encode(result); err != nil {
+		logger.Warn("server_write_failed", map[string]interface{}{
+			"agent": result.Agent,
+			"error": err.Error(),
+		})
+	}
+}
+
+// serveClaim runs a forwarded claim command against the server's database,
+// under the claim config as it is on disk now.
+// handleServerConn has already checked that the workspace and database flags
+// in args lead to it; the busy timeout flag is ignored in favour of the
+// server's own.
+func serveClaim(
+	ctx context.Context,
+	args []string,
+	repo application.IssueRepositoryPort,
+	configs *infrastructure.ClaimConfigLoader,
+	logger application.LoggerPort,
+) application.ClaimIssueResult {
+	cfg, err := parseFlagsFromArgs(args)
+	if err != nil {
+		return errorResult("", domain.ErrCodeInvalidArgument, err.Error())
+	}
+	cfg, err = applyCurrentClaimConfig(cfg, configs)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	req, err := buildClaimRequest(cfg)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	return executeClaim(ctx, cfg, req, repo, logger)
+}
+
+// sameFile reports whether two paths name the same existing file.
+func sameFile(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// claimViaServer forwards the claim to a `bd-claim serve` listening next to
+// the database. ok is false when no server answers, and the caller should
+// claim directly instead.
+func claimViaServer(ctx context.Context, cfg config, logger application.LoggerPort) (application.ClaimIssueResult, bool) {
+	// Only a claim parsed from command-line arguments can be forwarded
+	if cfg.args == nil {
+		return application.ClaimIssueResult{}, false
+	}
+
+	dbPath, err := resolveDbPath(cfg, logger)
+	if err != nil {
+		return application.ClaimIssueResult{}, false
+	}
+	socket := socketPath(dbPath)
+	if _, err := os.Stat(socket); err != nil {
+		return application.ClaimIssueResult{}, false
+	}
+
+	conn, err := net.DialTimeout("unix", socket, serverDialTimeout)
+	if err != nil {
+		logger.Debug("server_unavailable", map[string]interface{}{
+			"socket": socket,
+			"error":  err.Error(),
+		})
+		return application.ClaimIssueResult{}, false
+	}
+	defer conn.Close()
+
+	logger.Debug("claim_via_server", map[string]interface{}{
+		"socket": socket,
+	})
+
+	// The server refuses claims meant for another database
+	absDbPath, err := filepath.Abs(dbPath)
+	if err != nil {
+		return application.ClaimIssueResult{}, false
+	}
+	if err := json.NewEncoder(conn).Encode(serverRequest{Args: cfg.args, DbPath: absDbPath}); err != nil {
+		return errorResult(cfg.agent, domain.ErrCodeUnexpected, "failed to send claim to server: "+err.Error()), true
+	}
+
+	// Hanging up tells the server to stop waiting; it still answers
+	if unixConn, ok := conn.(*net.UnixConn); ok {
+		stop := context.AfterFunc(ctx, func() {
+			unixConn.CloseWrite()
+		})
+		defer stop()
+	}
+
+	var result application.ClaimIssueResult
+	if err := json.NewDecoder(conn).Decode(&result); err != nil {
+		return errorResult(cfg.agent, domain.ErrCodeUnexpected, "no response from server: "+err.Error()), true
+	}
+	return result, true
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// startTestServer runs `bd-claim serve` for workspace until the test ends.
+func startTestServer(t *testing.T, workspace string) string {
+	t.Helper()
+
+	cfg, err := parseServeFlags([]string{"--workspace", workspace, "--log-level", "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, cfg) }()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve returned error: %v", err)
+		}
+	})
+
+	socket := filepath.Join(workspace, ".beads", socketName)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			return socket
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server did not start")
+	return ""
+}
+
+func TestParseServeFlags(t *testing.T) {
+	cfg, err := parseServeFlags([]string{"--socket", "/tmp/x.sock", "--db", "/tmp/beads.db", "--http", "127.0.0.1:7878"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "serve" || cfg.socket != "/tmp/x.sock" || cfg.dbPath != "/tmp/beads.db" || cfg.httpAddr != "127.0.0.1:7878" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.logLevel != "info" {
+		t.Errorf("expected serve to log at info by default, got %s", cfg.logLevel)
+	}
+}
+
+func TestServe_ClaimViaServer(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+	insertIssue(t, tmpDir, "test-2", "Second", 2)
+
+	startTestServer(t, tmpDir)
+
+	cfg, err := parseFlagsFromArgs([]string{"--agent", "agent-a", "--workspace", tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := infrastructure.NewJSONLogger(infrastructure.LogLevelError)
+
+	result, ok := claimViaServer(context.Background(), cfg, logger)
+	if !ok {
+		t.Fatal("expected the claim to go through the server")
+	}
+	if result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// run picks the server up transparently
+	cfg, _ = parseFlagsFromArgs([]string{"--agent", "agent-b", "--workspace", tmpDir})
+	result = run(cfg)
+	if result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-2" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// Invalid requests are answered, not dropped
+	cfg, _ = parseFlagsFromArgs([]string{"--agent", "", "--workspace", tmpDir})
+	result, ok = claimViaServer(context.Background(), cfg, logger)
+	if !ok || result.Status != "error" || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT from server, got %+v", result)
+	}
+}
+
+func TestServe_WaitInterruptedByClient(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	startTestServer(t, tmpDir)
+
+	cfg, _ := parseFlagsFromArgs([]string{"--agent", "agent-a", "--workspace", tmpDir, "--wait", "--poll-interval", "10ms"})
+	logger := infrastructure.NewJSONLogger(infrastructure.LogLevelError)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	result, ok := claimViaServer(ctx, cfg, logger)
+	if !ok {
+		t.Fatal("expected the claim to go through the server")
+	}
+	if result.Wait == nil || result.Wait.Outcome != "interrupted" {
+		t.Errorf("expected interrupted wait, got %+v", result)
+	}
+}
+
+func TestServe_AlreadyRunning(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	startTestServer(t, tmpDir)
+
+	cfg, _ := parseServeFlags([]string{"--workspace", tmpDir, "--log-level", "error"})
+	if err := serve(context.Background(), cfg); err == nil {
+		t.Error("expected a second server to refuse to start")
+	}
+}
+
+func TestServe_StaleSocket(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+
+	// A socket file nobody listens on
+	socket := filepath.Join(tmpDir, ".beads", socketName)
+	if err := os.WriteFile(socket, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ := parseFlagsFromArgs([]string{"--agent", "agent-a", "--workspace", tmpDir})
+	logger := infrastructure.NewJSONLogger(infrastructure.LogLevelError)
+	if _, ok := claimViaServer(context.Background(), cfg, logger); ok {
+		t.Fatal("expected no server to answer")
+	}
+
+	// The client falls back to the database
+	result := run(cfg)
+	if result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// And a new server replaces the stale file
+	startTestServer(t, tmpDir)
+}
+
+func TestServe_OtherDatabase(t *testing.T) {
+	served, cleanupServed := setupTestDB(t)
+	defer cleanupServed()
+	other, cleanupOther := setupTestDB(t)
+	defer cleanupOther()
+	insertIssue(t, served, "served-1", "Served", 1)
+	insertIssue(t, other, "other-1", "Other", 1)
+
+	// A server for one database listening where clients of another look
+	socket := filepath.Join(other, ".beads", socketName)
+	cfg, _ := parseServeFlags([]string{"--workspace", served, "--socket", socket, "--log-level", "error"})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, cfg) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a 0600 socket, got %v (%v)", info, err)
+	}
+
+	clientCfg, _ := parseFlagsFromArgs([]string{"--agent", "agent-a", "--workspace", other})
+	logger := infrastructure.NewJSONLogger(infrastructure.LogLevelError)
+	result, ok := claimViaServer(context.Background(), clientCfg, logger)
+	if !ok || result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Fatalf("expected the server to refuse another database, got %+v", result)
+	}
+	if !strings.Contains(result.Error.Message, "serves") {
+		t.Errorf("expected the message to name the served database, got %q", result.Error.Message)
+	}
+}
+
+func TestServe_ReloadsConfig(t *testing.T) {
+	tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, tmpDir, "test-1", "First", 1)
+	insertIssue(t, tmpDir, "test-2", "Second", 2)
+
+	startTestServer(t, tmpDir)
+
+	logger := infrastructure.NewJSONLogger(infrastructure.LogLevelError)
+	cfg, _ := parseFlagsFromArgs([]string{"--agent", "agent-a", "--workspace", tmpDir})
+	result, ok := claimViaServer(context.Background(), cfg, logger)
+	if !ok || result.Status != "ok" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// Edited while the server runs; the next claim sees it
+	configPath := filepath.Join(tmpDir, ".beads", infrastructure.ClaimConfigFile)
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, ok = claimViaServer(context.Background(), cfg, logger)
+	if !ok || result.Error == nil || result.Error.Code != "WIP_LIMIT_REACHED" {
+		t.Fatalf("expected the new limit to apply, got %+v", result)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"max_in_progress": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, ok = claimViaServer(context.Background(), cfg, logger)
+	if !ok || result.Status != "error" {
+		t.Fatalf("expected a broken config to be reported, got %+v", result)
+	}
+
+	if err := os.Remove(configPath); err != nil {
+		t.Fatal(err)
+	}
+	result, ok = claimViaServer(context.Background(), cfg, logger)
+	if !ok || result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-2" {
+		t.Fatalf("expected the limit to go with the file, got %+v", result)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// forwardedSignals are passed on to the supervised command, which runs in a
+// process group of its own and so only receives them this way.
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// runSuperviseApp is the entrypoint for `bd-claim run`.
+func runSuperviseApp(args []string) int {
+	cfg, err := parseSuperviseFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	result, exitCode := supervise(cfg)
+	if code := outputResult(cfg, result); code != 0 && exitCode == 0 {
+		return code
+	}
+	return exitCode
+}
+
+// parseSuperviseFlags parses `[claim flags] -- command [args...]`.
+func parseSuperviseFlags(args []string) (config, error) {
+	flags, command, err := splitCommand("run", args)
+	if err != nil {
+		return config{}, err
+	}
+
+	cfg, err := parseFlagsFromArgs(flags)
+	if err != nil {
+		return config{}, err
+	}
+	cfg.command = "run"
+	cfg.exec = command
+
+	return cfg, nil
+}
+
+// splitCommand separates a subcommand's flags from the command after `--`.
+func splitCommand(name string, args []string) ([]string, []string, error) {
+	for i, arg := range args {
+		if arg == "--" && i < len(args)-1 {
+			return args[:i], args[i+1:], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("usage: bd-claim %s [flags] -- command [args...] (the command runs in its own process group and gets no terminal on stdin)", name)
+}
+
+// supervise claims an issue, runs the command on it and then closes the
+// issue if the command succeeded or releases it otherwise. It returns the
+// close or release result, or LEASE_LOST if another agent took the issue
+// over, and the command's exit code.
+func supervise(cfg config) (application.ClaimIssueResult, int) {
+	if cfg.count > 1 {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--count cannot be combined with run"), 0
+	}
+	if cfg.dryRun {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--dry-run cannot be combined with run"), 0
+	}
+
+	claimed := run(cfg)
+	if claimed.Status == "error" || claimed.Issue == nil {
+		return claimed, 0
+	}
+
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		return handleDomainError(cfg.agent, err), 1
+	}
+	defer repo.Close()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	exitCode, outcome, leaseLost := runClaimedCommand(cfg, claimed, repo, logger, signals, nil)
+	return finishClaim(claimed, exitCode, outcome, leaseLost, repo, logger), exitCode
+}
+
+// finishClaim closes the claimed issue if its command succeeded and releases
+// it with the outcome as the reason otherwise. An issue whose lease was lost
+// belongs to another agent and is left alone.
+func finishClaim(
+	claimed application.ClaimIssueResult,
+	exitCode int,
+	outcome string,
+	leaseLost bool,
+	repo application.IssueRepositoryPort,
+	logger application.LoggerPort,
+) application.ClaimIssueResult {
+	if leaseLost {
+		result := errorResult(claimed.Agent, domain.ErrCodeLeaseLost, fmt.Sprintf(
+			"lease on %s was lost to another agent and the command was stopped; %s", claimed.Issue.ID, outcome))
+		result.Issue = claimed.Issue
+		return result
+	}
+
+	agent := domain.AgentName(claimed.Agent)
+	issueID := domain.IssueId(claimed.Issue.ID)
+	clock := infrastructure.NewSystemClock()
+
+	if exitCode == 0 {
+		useCase := application.NewCompleteIssueUseCase(repo, clock, logger)
+		return useCase.Execute(context.Background(), application.CompleteIssueRequest{
+			Agent:   agent,
+			IssueID: issueID,
+		})
+	}
+
+	useCase := application.NewReleaseIssueUseCase(repo, clock, logger)
+	return useCase.Execute(context.Background(), application.ReleaseIssueRequest{
+		Agent:   agent,
+		IssueID: issueID,
+		Reason:  outcome,
+	})
+}
+
+// runClaimedCommand runs cfg.exec with the claimed issue and extraEnv in its
+// environment, forwarding signals and heartbeating leased claims until it
+// exits. It returns the exit code, a description of how the command ended,
+// and whether it was stopped because the lease was lost.
+func runClaimedCommand(
+	cfg config,
+	claimed application.ClaimIssueResult,
+	repo application.IssueRepositoryPort,
+	logger application.LoggerPort,
+	signals <-chan os.Signal,
+	extraEnv []string,
+) (int, string, bool) {
+	issueFile, err := writeIssueFile(claimed.Issue)
+	if err != nil {
+		return 1, "failed to write issue file: " + err.Error(), false
+	}
+	defer os.Remove(issueFile)
+
+	cmd := exec.Command(cfg.exec[0], cfg.exec[1:]...)
+	cmd.Env = append(append(os.Environ(), issueEnv(claimed, issueFile)...), extraEnv...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	detachedStdin := setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 127, "command failed to start: " + err.Error(), false
+	}
+
+	logger.Info("command_started", map[string]interface{}{
+		"agent":    claimed.Agent,
+		"issue_id": claimed.Issue.ID,
+		"pid":      cmd.Process.Pid,
+	})
+	if detachedStdin {
+		logger.Warn("command_stdin_detached", map[string]interface{}{
+			"agent":    claimed.Agent,
+			"issue_id": claimed.Issue.ID,
+			"reason":   "the command runs in its own process group and cannot read the terminal",
+		})
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	// Keep a leased claim alive while the command works on it
+	var heartbeats <-chan time.Time
+	if cfg.lease > 0 {
+		ticker := time.NewTicker(cfg.lease / 3)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+	heartbeat := application.NewHeartbeatUseCase(repo, infrastructure.NewSystemClock(), logger)
+	leaseLost := false
+
+	for {
+		select {
+		case sig := <-signals:
+			signalCommand(cmd, sig)
+		case <-heartbeats:
+			result := heartbeat.Execute(context.Background(), application.HeartbeatRequest{
+				Agent:   domain.AgentName(claimed.Agent),
+				IssueID: domain.IssueId(claimed.Issue.ID),
+			})
+			if result.Error != nil && result.Error.Code == string(domain.ErrCodeLeaseLost) {
+				// Another agent owns the issue now; stop duplicating its work
+				logger.Warn("lease_lost", map[string]interface{}{
+					"agent":    claimed.Agent,
+					"issue_id": claimed.Issue.ID,
+				})
+				signalCommand(cmd, syscall.SIGTERM)
+				heartbeats = nil
+				leaseLost = true
+			}
+		case err := <-done:
+			exitCode, outcome := exitStatus(err)
+			return exitCode, outcome, leaseLost
+		}
+	}
+}
+
+// exitStatus converts the result of exec.Cmd.Wait into a shell-style exit
+// code and a description for the release comment.
+func exitStatus(err error) (int, string) {
+	if err == nil {
+		return 0, "command exited with status 0"
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1, "command failed: " + err.Error()
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), "command was killed by signal " + status.Signal().String()
+	}
+	return exitErr.ExitCode(), fmt.Sprintf("command exited with status %d", exitErr.ExitCode())
+}
+
+// issueEnv describes the claimed issue to the supervised command.
+func issueEnv(claimed application.ClaimIssueResult, issueFile string) []string {
+	issue := claimed.Issue
+	env := []string{
+		"BD_CLAIM_AGENT=" + claimed.Agent,
+		"BD_CLAIM_ISSUE_ID=" + issue.ID,
+		"BD_CLAIM_ISSUE_TITLE=" + issue.Title,
+		"BD_CLAIM_ISSUE_PRIORITY=" + strconv.Itoa(issue.Priority),
+		"BD_CLAIM_ISSUE_TYPE=" + issue.IssueType,
+		"BD_CLAIM_ISSUE_LABELS=" + strings.Join(issue.Labels, ","),
+		"BD_CLAIM_ISSUE_FILE=" + issueFile,
+	}
+	if issue.LeaseExpiresAt != nil {
+		env = append(env, "BD_CLAIM_LEASE_EXPIRES_AT="+*issue.LeaseExpiresAt)
+	}
+	return env
+}
+
+// writeIssueFile writes the claimed issue as JSON to a temporary file.
+func writeIssueFile(issue *application.IssueDTO) (string, error) {
+	file, err := os.CreateTemp("", "bd-claim-issue-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(issue); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/application"
+)
+
+func TestParseSuperviseFlags(t *testing.T) {
+	cfg, err := parseSuperviseFlags([]string{"--agent", "test-agent", "--label", "backend", "--", "./work.sh", "--fast"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "run" || cfg.agent != "test-agent" || len(cfg.labels) != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if strings.Join(cfg.exec, " ") != "./work.sh --fast" {
+		t.Errorf("unexpected command: %v", cfg.exec)
+	}
+
+	for _, args := range [][]string{
+		{"--agent", "test-agent"},
+		{"--agent", "test-agent", "--"},
+		{"--bogus", "--", "true"},
+	} {
+		if _, err := parseSuperviseFlags(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+// superviseInWorkspace runs `bd-claim run` against a workspace and returns
+// the result and exit code.
+func superviseInWorkspace(t *testing.T, workspace string, command ...string) (application.ClaimIssueResult, int) {
+	t.Helper()
+
+	args := append([]string{"--agent", "test-agent", "--workspace", workspace, "--"}, command...)
+	cfg, err := parseSuperviseFlags(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return supervise(cfg)
+}
+
+func TestSupervise_SuccessClosesIssue(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	out := filepath.Join(t.TempDir(), "env")
+	result, exitCode := superviseInWorkspace(t, workspaceRoot, "sh", "-c",
+		`echo "$BD_CLAIM_AGENT $BD_CLAIM_ISSUE_ID $BD_CLAIM_ISSUE_TITLE" > `+out+` && cat "$BD_CLAIM_ISSUE_FILE" >> `+out)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if result.Status != "ok" || result.Issue == nil || result.Issue.Status != "closed" || result.Issue.ClosedAt == nil {
+		t.Fatalf("expected closed issue, got %+v", result)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	if lines[0] != "test-agent test-123 Test Issue" {
+		t.Errorf("unexpected environment: %q", lines[0])
+	}
+	var issue application.IssueDTO
+	if err := json.Unmarshal([]byte(lines[1]), &issue); err != nil || issue.ID != "test-123" || issue.Status != "in_progress" {
+		t.Errorf("unexpected issue file: %s (%v)", lines[1], err)
+	}
+}
+
+func TestSupervise_FailureReleasesIssue(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	result, exitCode := superviseInWorkspace(t, workspaceRoot, "sh", "-c", "exit 3")
+
+	if exitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", exitCode)
+	}
+	if result.Status != "ok" || result.Issue == nil || result.Issue.Status != "open" || result.Issue.Assignee != nil {
+		t.Errorf("expected released issue, got %+v", result)
+	}
+}
+
+func TestSupervise_CommandNotFound(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	result, exitCode := superviseInWorkspace(t, workspaceRoot, "/nonexistent/command")
+
+	if exitCode != 127 {
+		t.Errorf("expected exit code 127, got %d", exitCode)
+	}
+	if result.Issue == nil || result.Issue.Status != "open" {
+		t.Errorf("expected released issue, got %+v", result)
+	}
+}
+
+func TestSupervise_NoIssue(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	result, exitCode := superviseInWorkspace(t, workspaceRoot, "touch", marker)
+
+	if exitCode != 0 || result.Status != "ok" || result.Issue != nil {
+		t.Errorf("expected no issue and exit code 0, got %d %+v", exitCode, result)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected the command not to run without an issue")
+	}
+}
+
+func TestRunApp_Run(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	exitCode := runApp([]string{"run", "--agent", "test-agent", "--workspace", workspaceRoot, "--", "sh", "-c", "echo working; exit 2"})
+	if exitCode != 2 {
+		t.Errorf("expected exit code 2, got %d", exitCode)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "working" {
+		t.Errorf("expected command output first, got %q", lines[0])
+	}
+	var result application.ClaimIssueResult
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &result); err != nil {
+		t.Fatalf("expected JSON result last: %v", err)
+	}
+	if result.Issue == nil || result.Issue.Status != "open" {
+		t.Errorf("expected released issue, got %+v", result)
+	}
+}
+
+func TestSupervise_LeaseLost(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	ready := filepath.Join(t.TempDir(), "ready")
+	cfg, err := parseSuperviseFlags([]string{"--agent", "test-agent", "--workspace", workspaceRoot, "--lease", "150ms", "--",
+		"sh", "-c", `trap 'exit 9' TERM; touch ` + ready + `; while true; do sleep 0.05; done`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type outcome struct {
+		result   application.ClaimIssueResult
+		exitCode int
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, exitCode := supervise(cfg)
+		done <- outcome{result, exitCode}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("command did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Another agent takes the issue over
+	db, err := sql.Open("sqlite3", filepath.Join(workspaceRoot, ".beads", "beads.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`UPDATE issues SET assignee = 'agent-b' WHERE id = 'test-123'`); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-done:
+		if got.exitCode != 9 {
+			t.Errorf("expected the stopped command's exit code 9, got %d", got.exitCode)
+		}
+		if got.result.Error == nil || got.result.Error.Code != "LEASE_LOST" {
+			t.Fatalf("expected LEASE_LOST, got %+v", got.result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not stop the command")
+	}
+
+	// The new owner's claim is untouched
+	var status, assignee string
+	if err := db.QueryRow(`SELECT status, assignee FROM issues WHERE id = 'test-123'`).Scan(&status, &assignee); err != nil {
+		t.Fatal(err)
+	}
+	if status != "in_progress" || assignee != "agent-b" {
+		t.Errorf("expected agent-b to keep the issue, got %s/%s", status, assignee)
+	}
+}
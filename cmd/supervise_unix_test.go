@@ -0,0 +1,133 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/application"
+)
+
+func TestSupervise_ForwardsSignals(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	ready := filepath.Join(t.TempDir(), "ready")
+	type outcome struct {
+		result   application.ClaimIssueResult
+		exitCode int
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, exitCode := superviseInWorkspace(t, workspaceRoot, "sh", "-c",
+			`trap 'exit 7' TERM; touch `+ready+`; while true; do sleep 0.05; done`)
+		done <- outcome{result, exitCode}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("command did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The supervisor catches SIGTERM and hands it to the command
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-done:
+		if got.exitCode != 7 {
+			t.Errorf("expected the command's exit code 7, got %d", got.exitCode)
+		}
+		if got.result.Issue == nil || got.result.Issue.Status != "open" {
+			t.Errorf("expected released issue, got %+v", got.result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not finish")
+	}
+}
+
+func TestSupervise_OwnProcessGroup(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "pid")
+	stop := filepath.Join(dir, "stop")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		superviseInWorkspace(t, workspaceRoot, "sh", "-c",
+			`echo $$ > `+pidFile+`.tmp; mv `+pidFile+`.tmp `+pidFile+`; while [ ! -e `+stop+` ]; do sleep 0.05; done`)
+	}()
+	defer func() {
+		os.WriteFile(stop, nil, 0600)
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var data []byte
+	for {
+		var err error
+		if data, err = os.ReadFile(pidFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("command did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A terminal's SIGINT to our group would not reach the command directly
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pgid != pid || pgid == syscall.Getpgrp() {
+		t.Errorf("expected the command to lead its own process group, got %d (ours %d)", pgid, syscall.Getpgrp())
+	}
+}
+
+func TestSetProcessGroup_Stdin(t *testing.T) {
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	file, err := os.Create(filepath.Join(t.TempDir(), "input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// Only a terminal is taken away, and the caller is told
+	for _, stdin := range []*os.File{null, file} {
+		cmd := exec.Command("true")
+		cmd.Stdin = stdin
+		if setProcessGroup(cmd) || cmd.Stdin != stdin {
+			t.Errorf("%s: expected stdin to be kept", stdin.Name())
+		}
+		if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+			t.Errorf("%s: expected a process group of its own", stdin.Name())
+		}
+	}
+}
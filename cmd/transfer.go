@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ccheney/bd-claim/internal/application"
+	"github.com/ccheney/bd-claim/internal/domain"
+	"github.com/ccheney/bd-claim/internal/infrastructure"
+)
+
+// runTransferApp is the entrypoint for `bd-claim transfer`.
+func runTransferApp(args []string) int {
+	cfg, err := parseTransferFlags(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error parsing flags: %s\n", err.Error())
+		return 1
+	}
+
+	result := runTransfer(cfg)
+	return outputResult(cfg, result)
+}
+
+func parseTransferFlags(args []string) (config, error) {
+	cfg := config{command: "transfer"}
+	fs := flag.NewFlagSet("bd-claim transfer", flag.ContinueOnError)
+	fs.SetOutput(io.Discard) // Suppress default usage output
+
+	registerCommonFlags(fs, &cfg)
+	fs.StringVar(&cfg.issueID, "issue", "", "ID of the claimed issue to hand over (required)")
+	fs.StringVar(&cfg.transferTo, "to", "", "Agent taking over the issue (required)")
+	fs.StringVar(&cfg.reason, "reason", "", "Why the issue is being handed over (recorded in the event)")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+func runTransfer(cfg config) application.ClaimIssueResult {
+	agent, err := parseAgent(cfg.agent)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+
+	if cfg.issueID == "" {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--issue flag is required")
+	}
+	if cfg.transferTo == "" {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "--to flag is required")
+	}
+	to, err := domain.NewAgentName(cfg.transferTo)
+	if err != nil {
+		return errorResult(cfg.agent, domain.ErrCodeInvalidArgument, "invalid --to agent: "+err.Error())
+	}
+
+	logger := infrastructure.NewJSONLogger(parseLogLevel(cfg.logLevel))
+
+	repo, err := openRepository(cfg, logger)
+	if err != nil {
+		return handleDomainError(cfg.agent, err)
+	}
+	defer repo.Close()
+
+	clock := infrastructure.NewSystemClock()
+	useCase := application.NewTransferIssueUseCase(repo, clock, logger)
+
+	req := application.TransferIssueRequest{
+		Agent:   agent,
+		To:      to,
+		IssueID: domain.IssueId(cfg.issueID),
+		Reason:  cfg.reason,
+	}
+
+	return useCase.Execute(context.Background(), req)
+}
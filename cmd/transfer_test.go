@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/application"
+)
+
+func TestParseTransferFlags(t *testing.T) {
+	cfg, err := parseTransferFlags([]string{"--agent", "planner", "--to", "specialist", "--issue", "bd-1", "--reason", "needs a DB expert"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.command != "transfer" {
+		t.Errorf("expected command 'transfer', got '%s'", cfg.command)
+	}
+	if cfg.agent != "planner" || cfg.transferTo != "specialist" || cfg.issueID != "bd-1" || cfg.reason != "needs a DB expert" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestRunTransfer_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config
+	}{
+		{"missing agent", config{transferTo: "specialist", issueID: "bd-1"}},
+		{"missing issue", config{agent: "planner", transferTo: "specialist"}},
+		{"missing target", config{agent: "planner", issueID: "bd-1"}},
+		{"invalid target", config{agent: "planner", transferTo: "spec ialist", issueID: "bd-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runTransfer(tt.cfg)
+			if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+				t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+			}
+		})
+	}
+}
+
+func TestRunApp_ClaimThenTransfer(t *testing.T) {
+	workspaceRoot, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertIssue(t, workspaceRoot, "test-123", "Test Issue", 1)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	if code := runApp([]string{"--agent", "planner", "--workspace", workspaceRoot}); code != 0 {
+		t.Fatalf("claim failed with exit code %d: %s", code, buf.String())
+	}
+
+	transfer := func(agent, to string) (int, application.ClaimIssueResult) {
+		buf.Reset()
+		code := runApp([]string{"transfer", "--agent", agent, "--to", to, "--issue", "test-123", "--workspace", workspaceRoot})
+		var result application.ClaimIssueResult
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v: %s", err, buf.String())
+		}
+		return code, result
+	}
+
+	// Only the owner can hand the issue over
+	if code, result := transfer("specialist", "other"); code != 1 || result.Error == nil || result.Error.Code != "NOT_OWNER" {
+		t.Errorf("expected NOT_OWNER error, got %d %+v", code, result.Error)
+	}
+
+	code, result := transfer("planner", "specialist")
+	if code != 0 {
+		t.Fatalf("transfer failed with exit code %d: %+v", code, result.Error)
+	}
+	if result.Issue == nil || result.Issue.Assignee == nil || *result.Issue.Assignee != "specialist" {
+		t.Fatalf("expected issue assigned to specialist, got %+v", result.Issue)
+	}
+	if result.Issue.TransferredFrom == nil || *result.Issue.TransferredFrom != "planner" {
+		t.Errorf("expected transferred_from 'planner', got %v", result.Issue.TransferredFrom)
+	}
+
+	// The new owner can finish it
+	buf.Reset()
+	if code := runApp([]string{"complete", "--agent", "specialist", "--issue", "test-123", "--workspace", workspaceRoot}); code != 0 {
+		t.Errorf("complete by new owner failed with exit code %d: %s", code, buf.String())
+	}
+}
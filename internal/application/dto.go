@@ -1,13 +1,72 @@
 package application
 
-import "github.com/ccheney/bd-claim/internal/domain"
+import (
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
 
 // ClaimIssueRequest represents a request to claim an issue.
 type ClaimIssueRequest struct {
 	Agent     domain.AgentName
 	Filters   domain.ClaimFilters
+	Options   domain.ClaimOptions
 	DryRun    bool
 	TimeoutMs int
+	// Count is how many issues to claim at once; zero means one.
+	Count int
+	// IssueID, if set, claims that issue only instead of the next ready one.
+	IssueID domain.IssueId
+}
+
+// WaitOptions controls how ClaimIssueUseCase.ExecuteWait polls for work.
+type WaitOptions struct {
+	// PollInterval is the average delay between claim attempts. Each delay
+	// is jittered so waiting agents do not poll in lockstep.
+	PollInterval time.Duration
+}
+
+// Wait outcomes reported in WaitDTO.
+const (
+	WaitOutcomeClaimed     = "claimed"
+	WaitOutcomeTimeout     = "timeout"
+	WaitOutcomeInterrupted = "interrupted"
+	WaitOutcomeError       = "error"
+)
+
+// ReleaseIssueRequest represents a request to give a claimed issue back.
+type ReleaseIssueRequest struct {
+	Agent   domain.AgentName
+	IssueID domain.IssueId
+	Reason  string
+}
+
+// CompleteIssueRequest represents a request to close a finished claim.
+type CompleteIssueRequest struct {
+	Agent   domain.AgentName
+	IssueID domain.IssueId
+	Reason  string
+}
+
+// TransferIssueRequest represents a request to hand a claimed issue to
+// another agent.
+type TransferIssueRequest struct {
+	Agent   domain.AgentName
+	To      domain.AgentName
+	IssueID domain.IssueId
+	Reason  string
+}
+
+// HeartbeatRequest represents a request to prove an agent is still working
+// on its claimed issue.
+type HeartbeatRequest struct {
+	Agent   domain.AgentName
+	IssueID domain.IssueId
+}
+
+// ListAgentIssuesRequest asks for the issues an agent has in progress.
+type ListAgentIssuesRequest struct {
+	Agent domain.AgentName
 }
 
 // ClaimIssueResult represents the result of a claim attempt.
@@ -15,29 +74,57 @@ type ClaimIssueResult struct {
 	Status  string         `json:"status"`
 	Agent   string         `json:"agent"`
 	Issue   *IssueDTO      `json:"issue"`
+	Issues  []*IssueDTO    `json:"issues,omitempty"`
 	Filters *FiltersDTO    `json:"filters,omitempty"`
+	Wait    *WaitDTO       `json:"wait,omitempty"`
 	Error   *ClaimErrorDTO `json:"error,omitempty"`
 }
 
+// WaitDTO reports how a waiting claim ended.
+type WaitDTO struct {
+	WaitedMs int64  `json:"waited_ms"`
+	Attempts int    `json:"attempts"`
+	Outcome  string `json:"outcome"`
+}
+
 // IssueDTO is a data transfer object for issue data.
 type IssueDTO struct {
-	ID        string   `json:"id"`
-	Title     string   `json:"title"`
-	Status    string   `json:"status"`
-	Assignee  *string  `json:"assignee"`
-	Priority  int      `json:"priority"`
-	Labels    []string `json:"labels"`
-	IssueType string   `json:"issue_type,omitempty"`
-	CreatedAt string   `json:"created_at"`
-	UpdatedAt string   `json:"updated_at"`
+	ID              string   `json:"id"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`
+	Assignee        *string  `json:"assignee"`
+	Priority        int      `json:"priority"`
+	Labels          []string `json:"labels"`
+	IssueType       string   `json:"issue_type,omitempty"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+	ClosedAt        *string  `json:"closed_at,omitempty"`
+	LeaseExpiresAt  *string  `json:"lease_expires_at,omitempty"`
+	LastHeartbeatAt *string  `json:"last_heartbeat_at,omitempty"`
+	ReclaimedFrom   *string  `json:"reclaimed_from,omitempty"`
+	TransferredFrom *string  `json:"transferred_from,omitempty"`
+	Unblocks        *int     `json:"unblocks,omitempty"`
 }
 
 // FiltersDTO is a data transfer object for claim filters.
 type FiltersDTO struct {
-	OnlyUnassigned bool     `json:"only_unassigned"`
-	IncludeLabels  []string `json:"include_labels"`
-	ExcludeLabels  []string `json:"exclude_labels"`
-	MinPriority    *int     `json:"min_priority,omitempty"`
+	OnlyUnassigned    bool           `json:"only_unassigned"`
+	IncludeLabels     []string       `json:"include_labels"`
+	ExcludeLabels     []string       `json:"exclude_labels"`
+	Where             string         `json:"where,omitempty"`
+	IncludeTypes      []string       `json:"include_types,omitempty"`
+	ExcludeTypes      []string       `json:"exclude_types,omitempty"`
+	Parent            string         `json:"parent,omitempty"`
+	DirectChildren    bool           `json:"direct_children,omitempty"`
+	MinPriority       *int           `json:"min_priority,omitempty"`
+	MaxPriority       *int           `json:"max_priority,omitempty"`
+	LabelLimits       map[string]int `json:"label_limits,omitempty"`
+	ExclusivePrefixes []string       `json:"exclusive_prefixes,omitempty"`
+	Readiness         string         `json:"readiness,omitempty"`
+	Strategy          string         `json:"strategy,omitempty"`
+	PreferLabels      []string       `json:"prefer_labels,omitempty"`
+	TypeOrder         []string       `json:"type_order,omitempty"`
+	Profile           string         `json:"profile,omitempty"`
 }
 
 // ClaimErrorDTO is a data transfer object for claim errors.
@@ -46,41 +133,75 @@ type ClaimErrorDTO struct {
 	Message string `json:"message"`
 }
 
+// timestampFormat is how IssueDTO renders times.
+const timestampFormat = "2006-01-02T15:04:05.999999-07:00"
+
 // IssueToDTO converts a domain Issue to an IssueDTO.
 func IssueToDTO(issue *domain.Issue) *IssueDTO {
 	if issue == nil {
 		return nil
 	}
 
-	var assignee *string
-	if issue.Assignee != nil {
-		a := issue.Assignee.String()
-		assignee = &a
-	}
-
 	labels := make([]string, len(issue.Labels))
 	copy(labels, issue.Labels)
 
 	return &IssueDTO{
-		ID:        issue.ID.String(),
-		Title:     issue.Title,
-		Status:    string(issue.Status),
-		Assignee:  assignee,
-		Priority:  int(issue.Priority),
-		Labels:    labels,
-		IssueType: issue.IssueType,
-		CreatedAt: issue.CreatedAt.Format("2006-01-02T15:04:05.999999-07:00"),
-		UpdatedAt: issue.UpdatedAt.Format("2006-01-02T15:04:05.999999-07:00"),
+		ID:              issue.ID.String(),
+		Title:           issue.Title,
+		Status:          string(issue.Status),
+		Assignee:        agentToDTO(issue.Assignee),
+		Priority:        int(issue.Priority),
+		Labels:          labels,
+		IssueType:       issue.IssueType,
+		CreatedAt:       issue.CreatedAt.Format(timestampFormat),
+		UpdatedAt:       issue.UpdatedAt.Format(timestampFormat),
+		ClosedAt:        timeToDTO(issue.ClosedAt),
+		LeaseExpiresAt:  timeToDTO(issue.LeaseExpiresAt),
+		LastHeartbeatAt: timeToDTO(issue.LastHeartbeatAt),
+		ReclaimedFrom:   agentToDTO(issue.ReclaimedFrom),
+		TransferredFrom: agentToDTO(issue.TransferredFrom),
+		Unblocks:        issue.Unblocks,
+	}
+}
+
+// timeToDTO formats an optional time, keeping nil as nil.
+func timeToDTO(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(timestampFormat)
+	return &s
+}
+
+// agentToDTO converts an optional agent name, keeping nil as nil.
+func agentToDTO(agent *domain.AgentName) *string {
+	if agent == nil {
+		return nil
+	}
+	s := agent.String()
+	return &s
+}
+
+// IssuesToDTO converts domain Issues to IssueDTOs.
+func IssuesToDTO(issues []*domain.Issue) []*IssueDTO {
+	dtos := make([]*IssueDTO, len(issues))
+	for i, issue := range issues {
+		dtos[i] = IssueToDTO(issue)
 	}
+	return dtos
 }
 
 // FiltersToDTO converts domain ClaimFilters to FiltersDTO.
 func FiltersToDTO(filters domain.ClaimFilters) *FiltersDTO {
-	var minPriority *int
+	var minPriority, maxPriority *int
 	if filters.MinPriority != nil {
 		p := int(*filters.MinPriority)
 		minPriority = &p
 	}
+	if filters.MaxPriority != nil {
+		p := int(*filters.MaxPriority)
+		maxPriority = &p
+	}
 
 	includeLabels := filters.IncludeLabels
 	if includeLabels == nil {
@@ -92,10 +213,28 @@ func FiltersToDTO(filters domain.ClaimFilters) *FiltersDTO {
 		excludeLabels = []string{}
 	}
 
+	var where string
+	if filters.Where != nil {
+		where = filters.Where.String()
+	}
+
 	return &FiltersDTO{
-		OnlyUnassigned: filters.OnlyUnassigned,
-		IncludeLabels:  includeLabels,
-		ExcludeLabels:  excludeLabels,
-		MinPriority:    minPriority,
+		OnlyUnassigned:    filters.OnlyUnassigned,
+		IncludeLabels:     includeLabels,
+		ExcludeLabels:     excludeLabels,
+		Where:             where,
+		IncludeTypes:      filters.IncludeTypes,
+		ExcludeTypes:      filters.ExcludeTypes,
+		Parent:            filters.Parent.String(),
+		DirectChildren:    filters.DirectChildren,
+		MinPriority:       minPriority,
+		MaxPriority:       maxPriority,
+		LabelLimits:       filters.LabelLimits,
+		ExclusivePrefixes: filters.ExclusivePrefixes,
+		Readiness:         string(filters.Readiness),
+		Strategy:          string(filters.Strategy),
+		PreferLabels:      filters.PreferLabels,
+		TypeOrder:         filters.TypeOrder,
+		Profile:           filters.Profile,
 	}
 }
@@ -44,8 +44,8 @@ func TestIssueToDTO(t *testing.T) {
 	if dto.Assignee == nil || *dto.Assignee != "test-agent" {
 		t.Error("expected Assignee to be 'test-agent'")
 	}
-	if dto.Priority != 2 {
-		t.Errorf("expected Priority 2, got %d", dto.Priority)
+	if dto.Priority != 1 {
+		t.Errorf("expected Priority 1, got %d", dto.Priority)
 	}
 	if len(dto.Labels) != 2 {
 		t.Errorf("expected 2 labels, got %d", len(dto.Labels))
@@ -55,6 +55,31 @@ func TestIssueToDTO(t *testing.T) {
 	}
 }
 
+func TestIssueToDTO_Lease(t *testing.T) {
+	previous := domain.AgentName("old-agent")
+	expires := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	heartbeat := expires.Add(-time.Minute)
+	issue := &domain.Issue{
+		ID:              domain.IssueId("test-123"),
+		Status:          domain.StatusInProgress,
+		LeaseExpiresAt:  &expires,
+		ReclaimedFrom:   &previous,
+		LastHeartbeatAt: &heartbeat,
+	}
+
+	dto := IssueToDTO(issue)
+
+	if dto.LeaseExpiresAt == nil || *dto.LeaseExpiresAt != "2025-01-02T03:04:05+00:00" {
+		t.Errorf("unexpected LeaseExpiresAt: %v", dto.LeaseExpiresAt)
+	}
+	if dto.ReclaimedFrom == nil || *dto.ReclaimedFrom != "old-agent" {
+		t.Errorf("unexpected ReclaimedFrom: %v", dto.ReclaimedFrom)
+	}
+	if dto.LastHeartbeatAt == nil || *dto.LastHeartbeatAt != "2025-01-02T03:03:05+00:00" {
+		t.Errorf("unexpected LastHeartbeatAt: %v", dto.LastHeartbeatAt)
+	}
+}
+
 func TestIssueToDTO_NoAssignee(t *testing.T) {
 	issue := &domain.Issue{
 		ID:       domain.IssueId("test-123"),
@@ -69,11 +94,14 @@ func TestIssueToDTO_NoAssignee(t *testing.T) {
 
 func TestFiltersToDTO(t *testing.T) {
 	minPriority := domain.PriorityHigh
+	maxPriority := domain.PriorityMedium
 	filters := domain.ClaimFilters{
 		OnlyUnassigned: true,
 		IncludeLabels:  []string{"backend"},
 		ExcludeLabels:  []string{"wontfix"},
 		MinPriority:    &minPriority,
+		MaxPriority:    &maxPriority,
+		Readiness:      domain.ReadinessDependencies,
 	}
 
 	dto := FiltersToDTO(filters)
@@ -87,8 +115,14 @@ func TestFiltersToDTO(t *testing.T) {
 	if len(dto.ExcludeLabels) != 1 || dto.ExcludeLabels[0] != "wontfix" {
 		t.Error("expected ExcludeLabels to contain 'wontfix'")
 	}
-	if dto.MinPriority == nil || *dto.MinPriority != 2 {
-		t.Error("expected MinPriority to be 2")
+	if dto.MinPriority == nil || *dto.MinPriority != 1 {
+		t.Error("expected MinPriority to be 1")
+	}
+	if dto.MaxPriority == nil || *dto.MaxPriority != 2 {
+		t.Error("expected MaxPriority to be 2")
+	}
+	if dto.Readiness != "dependencies" {
+		t.Errorf("expected Readiness 'dependencies', got '%s'", dto.Readiness)
 	}
 }
 
@@ -114,4 +148,7 @@ func TestFiltersToDTO_Defaults(t *testing.T) {
 	if dto.MinPriority != nil {
 		t.Error("expected MinPriority to be nil")
 	}
+	if dto.MaxPriority != nil {
+		t.Error("expected MaxPriority to be nil")
+	}
 }
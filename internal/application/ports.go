@@ -15,6 +15,36 @@ type IssueRepositoryPort interface {
 		ctx context.Context,
 		agent domain.AgentName,
 		filters domain.ClaimFilters,
+		opts domain.ClaimOptions,
+	) (*domain.Issue, error)
+
+	// ClaimReadyIssues atomically claims up to n ready issues in one
+	// transaction, in claim order. Returns an empty slice if none were available.
+	ClaimReadyIssues(
+		ctx context.Context,
+		agent domain.AgentName,
+		filters domain.ClaimFilters,
+		n int,
+		opts domain.ClaimOptions,
+	) ([]*domain.Issue, error)
+
+	// ClaimIssueByID atomically claims the named issue if it is ready and
+	// matches filters. Fails with NOT_FOUND, ALREADY_CLAIMED or NOT_READY
+	// otherwise.
+	ClaimIssueByID(
+		ctx context.Context,
+		agent domain.AgentName,
+		issueID domain.IssueId,
+		filters domain.ClaimFilters,
+		opts domain.ClaimOptions,
+	) (*domain.Issue, error)
+
+	// FindIssueByID checks that the named issue could be claimed, without
+	// claiming it (for dry-run). Fails like ClaimIssueByID.
+	FindIssueByID(
+		ctx context.Context,
+		issueID domain.IssueId,
+		filters domain.ClaimFilters,
 	) (*domain.Issue, error)
 
 	// FindOneReadyIssue finds a ready issue without claiming it (for dry-run).
@@ -22,6 +52,57 @@ type IssueRepositoryPort interface {
 		ctx context.Context,
 		filters domain.ClaimFilters,
 	) (*domain.Issue, error)
+
+	// FindReadyIssues lists up to n ready issues in claim order without
+	// claiming them: the issues ClaimReadyIssues would take as a batch of n.
+	FindReadyIssues(
+		ctx context.Context,
+		filters domain.ClaimFilters,
+		n int,
+	) ([]*domain.Issue, error)
+
+	// FindIssuesByAssignee lists the issues the agent has in progress.
+	FindIssuesByAssignee(
+		ctx context.Context,
+		agent domain.AgentName,
+	) ([]*domain.Issue, error)
+
+	// ReleaseIssue atomically returns a claimed issue to the ready pool.
+	// Fails unless the issue is in progress and assigned to the agent.
+	ReleaseIssue(
+		ctx context.Context,
+		agent domain.AgentName,
+		issueID domain.IssueId,
+		reason string,
+	) (*domain.Issue, error)
+
+	// TransferIssue atomically reassigns a claimed issue from one agent to
+	// another without reopening it. Fails unless the issue is in progress and
+	// assigned to from.
+	TransferIssue(
+		ctx context.Context,
+		from domain.AgentName,
+		to domain.AgentName,
+		issueID domain.IssueId,
+		reason string,
+	) (*domain.Issue, error)
+
+	// CloseIssue atomically closes a claimed issue, recording the optional
+	// reason. Fails unless the issue is in progress and assigned to the agent.
+	CloseIssue(
+		ctx context.Context,
+		agent domain.AgentName,
+		issueID domain.IssueId,
+		reason string,
+	) (*domain.Issue, error)
+
+	// Heartbeat records that the agent is still working on its claimed issue
+	// and renews the claim's lease. Fails with LEASE_LOST once ownership moved.
+	Heartbeat(
+		ctx context.Context,
+		agent domain.AgentName,
+		issueID domain.IssueId,
+	) (*domain.Issue, error)
 }
 
 // WorkspaceDiscoveryPort defines the interface for discovering beads workspace.
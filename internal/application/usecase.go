@@ -3,6 +3,8 @@ package application
 import (
 	"context"
 	"errors"
+	"math/rand"
+	"time"
 
 	"github.com/ccheney/bd-claim/internal/domain"
 )
@@ -32,20 +34,48 @@ func (uc *ClaimIssueUseCase) Execute(ctx context.Context, req ClaimIssueRequest)
 	uc.logger.Info("claim_attempt_started", map[string]interface{}{
 		"agent":   req.Agent.String(),
 		"dry_run": req.DryRun,
+		"count":   req.Count,
 	})
 
+	if req.Count < 0 {
+		return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "count cannot be negative",
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
+	if !req.IssueID.IsEmpty() && req.Count > 1 {
+		return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "count cannot be combined with a specific issue id",
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
 	// Dry-run mode: just find without claiming
 	if req.DryRun {
 		return uc.executeDryRun(ctx, req)
 	}
 
+	if err := req.Options.Validate(); err != nil {
+		return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    err.Error(),
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
 	// Actual claim
-	issue, err := uc.repo.ClaimOneReadyIssue(ctx, req.Agent, req.Filters)
+	issues, err := uc.claim(ctx, req)
 	if err != nil {
-		return uc.handleError(req.Agent, req.Filters, err)
+		return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), err)
 	}
 
-	if issue == nil {
+	if len(issues) == 0 {
 		uc.logger.Info("no_issue_available", map[string]interface{}{
 			"agent": req.Agent.String(),
 		})
@@ -57,42 +87,458 @@ func (uc *ClaimIssueUseCase) Execute(ctx context.Context, req ClaimIssueRequest)
 		}
 	}
 
-	uc.logger.Info("issue_claimed", map[string]interface{}{
-		"agent":    req.Agent.String(),
-		"issue_id": issue.ID.String(),
-	})
+	for _, issue := range issues {
+		if issue.ReclaimedFrom != nil {
+			uc.logger.Info("issue_reclaimed", map[string]interface{}{
+				"agent":          req.Agent.String(),
+				"issue_id":       issue.ID.String(),
+				"previous_agent": issue.ReclaimedFrom.String(),
+			})
+		}
+
+		uc.logger.Info("issue_claimed", map[string]interface{}{
+			"agent":    req.Agent.String(),
+			"issue_id": issue.ID.String(),
+		})
+	}
 
 	return ClaimIssueResult{
 		Status:  "ok",
 		Agent:   req.Agent.String(),
-		Issue:   IssueToDTO(issue),
+		Issue:   IssueToDTO(issues[0]),
+		Issues:  IssuesToDTO(issues),
 		Filters: FiltersToDTO(req.Filters),
 	}
 }
 
+// claim claims the requested issue, a single ready issue, or a batch when the
+// request asks for more than one.
+func (uc *ClaimIssueUseCase) claim(ctx context.Context, req ClaimIssueRequest) ([]*domain.Issue, error) {
+	if !req.IssueID.IsEmpty() {
+		issue, err := uc.repo.ClaimIssueByID(ctx, req.Agent, req.IssueID, req.Filters, req.Options)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.Issue{issue}, nil
+	}
+
+	if req.Count > 1 {
+		return uc.repo.ClaimReadyIssues(ctx, req.Agent, req.Filters, req.Count, req.Options)
+	}
+
+	issue, err := uc.repo.ClaimOneReadyIssue(ctx, req.Agent, req.Filters, req.Options)
+	if err != nil || issue == nil {
+		return nil, err
+	}
+	return []*domain.Issue{issue}, nil
+}
+
 func (uc *ClaimIssueUseCase) executeDryRun(ctx context.Context, req ClaimIssueRequest) ClaimIssueResult {
-	issue, err := uc.repo.FindOneReadyIssue(ctx, req.Filters)
-	if err != nil {
-		return uc.handleError(req.Agent, req.Filters, err)
+	var issues []*domain.Issue
+	if !req.IssueID.IsEmpty() {
+		issue, err := uc.repo.FindIssueByID(ctx, req.IssueID, req.Filters)
+		if err != nil {
+			return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), err)
+		}
+		issues = []*domain.Issue{issue}
+	} else if req.Count > 1 {
+		var err error
+		issues, err = uc.repo.FindReadyIssues(ctx, req.Filters, req.Count)
+		if err != nil {
+			return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), err)
+		}
+	} else {
+		issue, err := uc.repo.FindOneReadyIssue(ctx, req.Filters)
+		if err != nil {
+			return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), err)
+		}
+		if issue != nil {
+			issues = []*domain.Issue{issue}
+		}
 	}
 
 	uc.logger.Info("dry_run_complete", map[string]interface{}{
 		"agent":       req.Agent.String(),
-		"found_issue": issue != nil,
+		"found_issue": len(issues) > 0,
 	})
 
+	result := ClaimIssueResult{
+		Status:  "ok",
+		Agent:   req.Agent.String(),
+		Filters: FiltersToDTO(req.Filters),
+	}
+	if len(issues) > 0 {
+		result.Issue = IssueToDTO(issues[0])
+		result.Issues = IssuesToDTO(issues)
+	}
+	return result
+}
+
+// retryableWhileWaiting lists the error codes that ExecuteWait treats as
+// "nothing to claim yet" rather than as failures.
+var retryableWhileWaiting = map[string]bool{
+	string(domain.ErrCodeSQLiteBusy):     true,
+	string(domain.ErrCodeNotReady):       true,
+	string(domain.ErrCodeAlreadyClaimed): true,
+}
+
+// RetryableWhileWaiting reports whether ExecuteWait keeps polling after a
+// claim fails with code.
+func RetryableWhileWaiting(code string) bool {
+	return retryableWhileWaiting[code]
+}
+
+// ExecuteWait repeats Execute until it claims an issue, a non-transient error
+// occurs, or ctx is done. The caller bounds the wait by giving ctx a deadline
+// or cancelling it on a signal.
+func (uc *ClaimIssueUseCase) ExecuteWait(ctx context.Context, req ClaimIssueRequest, opts WaitOptions) ClaimIssueResult {
+	if opts.PollInterval <= 0 {
+		return handleError(uc.logger, req.Agent, FiltersToDTO(req.Filters), &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "poll interval must be positive",
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
+	start := uc.clock.Now().Time()
+	for attempt := 1; ; attempt++ {
+		result := uc.Execute(ctx, req)
+
+		// A committed claim wins even if ctx finished meanwhile
+		if result.Error == nil && result.Issue != nil {
+			return uc.finishWait(result, start, attempt, WaitOutcomeClaimed)
+		}
+		if ctx.Err() != nil {
+			return uc.finishWait(uc.stoppedWaiting(req), start, attempt, waitOutcomeFor(ctx))
+		}
+		if result.Error != nil && !retryableWhileWaiting[result.Error.Code] {
+			return uc.finishWait(result, start, attempt, WaitOutcomeError)
+		}
+
+		uc.logger.Debug("wait_poll", map[string]interface{}{
+			"agent":   req.Agent.String(),
+			"attempt": attempt,
+		})
+
+		timer := time.NewTimer(jitter(opts.PollInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return uc.finishWait(uc.stoppedWaiting(req), start, attempt, waitOutcomeFor(ctx))
+		case <-timer.C:
+		}
+	}
+}
+
+// stoppedWaiting is the result when the wait ends before anything was claimed.
+func (uc *ClaimIssueUseCase) stoppedWaiting(req ClaimIssueRequest) ClaimIssueResult {
 	return ClaimIssueResult{
 		Status:  "ok",
 		Agent:   req.Agent.String(),
-		Issue:   IssueToDTO(issue),
+		Issue:   nil,
 		Filters: FiltersToDTO(req.Filters),
 	}
 }
 
-func (uc *ClaimIssueUseCase) handleError(agent domain.AgentName, filters domain.ClaimFilters, err error) ClaimIssueResult {
+func (uc *ClaimIssueUseCase) finishWait(result ClaimIssueResult, start time.Time, attempts int, outcome string) ClaimIssueResult {
+	waited := uc.clock.Now().Time().Sub(start)
+	uc.logger.Info("wait_finished", map[string]interface{}{
+		"agent":     result.Agent,
+		"outcome":   outcome,
+		"attempts":  attempts,
+		"waited_ms": waited.Milliseconds(),
+	})
+
+	result.Wait = &WaitDTO{
+		WaitedMs: waited.Milliseconds(),
+		Attempts: attempts,
+		Outcome:  outcome,
+	}
+	return result
+}
+
+// waitOutcomeFor tells a deadline apart from a cancellation (e.g. SIGINT).
+func waitOutcomeFor(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return WaitOutcomeTimeout
+	}
+	return WaitOutcomeInterrupted
+}
+
+// jitter spreads d by up to 20% either way.
+func jitter(d time.Duration) time.Duration {
+	spread := int64(d) / 5
+	if spread <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Int63n(2*spread+1)-spread)
+}
+
+// ReleaseIssueUseCase handles giving a claimed issue back to the ready pool.
+type ReleaseIssueUseCase struct {
+	repo   IssueRepositoryPort
+	clock  ClockPort
+	logger LoggerPort
+}
+
+// NewReleaseIssueUseCase creates a new ReleaseIssueUseCase.
+func NewReleaseIssueUseCase(
+	repo IssueRepositoryPort,
+	clock ClockPort,
+	logger LoggerPort,
+) *ReleaseIssueUseCase {
+	return &ReleaseIssueUseCase{
+		repo:   repo,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+// Execute performs the release operation.
+func (uc *ReleaseIssueUseCase) Execute(ctx context.Context, req ReleaseIssueRequest) ClaimIssueResult {
+	uc.logger.Info("release_attempt_started", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"issue_id": req.IssueID.String(),
+	})
+
+	if req.IssueID.IsEmpty() {
+		return handleError(uc.logger, req.Agent, nil, &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "issue id is required",
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
+	issue, err := uc.repo.ReleaseIssue(ctx, req.Agent, req.IssueID, req.Reason)
+	if err != nil {
+		return handleError(uc.logger, req.Agent, nil, err)
+	}
+
+	uc.logger.Info("issue_released", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"issue_id": issue.ID.String(),
+		"reason":   req.Reason,
+	})
+
+	return ClaimIssueResult{
+		Status: "ok",
+		Agent:  req.Agent.String(),
+		Issue:  IssueToDTO(issue),
+	}
+}
+
+// CompleteIssueUseCase handles closing an issue its owner has finished.
+type CompleteIssueUseCase struct {
+	repo   IssueRepositoryPort
+	clock  ClockPort
+	logger LoggerPort
+}
+
+// NewCompleteIssueUseCase creates a new CompleteIssueUseCase.
+func NewCompleteIssueUseCase(
+	repo IssueRepositoryPort,
+	clock ClockPort,
+	logger LoggerPort,
+) *CompleteIssueUseCase {
+	return &CompleteIssueUseCase{
+		repo:   repo,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+// Execute performs the complete operation.
+func (uc *CompleteIssueUseCase) Execute(ctx context.Context, req CompleteIssueRequest) ClaimIssueResult {
+	uc.logger.Info("complete_attempt_started", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"issue_id": req.IssueID.String(),
+	})
+
+	if req.IssueID.IsEmpty() {
+		return handleError(uc.logger, req.Agent, nil, &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "issue id is required",
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
+	issue, err := uc.repo.CloseIssue(ctx, req.Agent, req.IssueID, req.Reason)
+	if err != nil {
+		return handleError(uc.logger, req.Agent, nil, err)
+	}
+
+	uc.logger.Info("issue_closed", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"issue_id": issue.ID.String(),
+		"reason":   req.Reason,
+	})
+
+	return ClaimIssueResult{
+		Status: "ok",
+		Agent:  req.Agent.String(),
+		Issue:  IssueToDTO(issue),
+	}
+}
+
+// TransferIssueUseCase handles handing a claimed issue straight to another
+// agent.
+type TransferIssueUseCase struct {
+	repo   IssueRepositoryPort
+	clock  ClockPort
+	logger LoggerPort
+}
+
+// NewTransferIssueUseCase creates a new TransferIssueUseCase.
+func NewTransferIssueUseCase(
+	repo IssueRepositoryPort,
+	clock ClockPort,
+	logger LoggerPort,
+) *TransferIssueUseCase {
+	return &TransferIssueUseCase{
+		repo:   repo,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+// Execute performs the transfer operation.
+func (uc *TransferIssueUseCase) Execute(ctx context.Context, req TransferIssueRequest) ClaimIssueResult {
+	uc.logger.Info("transfer_attempt_started", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"to":       req.To.String(),
+		"issue_id": req.IssueID.String(),
+	})
+
+	var message string
+	switch {
+	case req.IssueID.IsEmpty():
+		message = "issue id is required"
+	case req.To == req.Agent:
+		message = "cannot transfer an issue to the agent already holding it"
+	}
+	if message != "" {
+		return handleError(uc.logger, req.Agent, nil, &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    message,
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
+	issue, err := uc.repo.TransferIssue(ctx, req.Agent, req.To, req.IssueID, req.Reason)
+	if err != nil {
+		return handleError(uc.logger, req.Agent, nil, err)
+	}
+
+	uc.logger.Info("issue_transferred", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"to":       req.To.String(),
+		"issue_id": issue.ID.String(),
+		"reason":   req.Reason,
+	})
+
+	return ClaimIssueResult{
+		Status: "ok",
+		Agent:  req.Agent.String(),
+		Issue:  IssueToDTO(issue),
+	}
+}
+
+// HeartbeatUseCase handles liveness reports from agents working on a claim.
+type HeartbeatUseCase struct {
+	repo   IssueRepositoryPort
+	clock  ClockPort
+	logger LoggerPort
+}
+
+// NewHeartbeatUseCase creates a new HeartbeatUseCase.
+func NewHeartbeatUseCase(
+	repo IssueRepositoryPort,
+	clock ClockPort,
+	logger LoggerPort,
+) *HeartbeatUseCase {
+	return &HeartbeatUseCase{
+		repo:   repo,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+// Execute performs the heartbeat operation.
+func (uc *HeartbeatUseCase) Execute(ctx context.Context, req HeartbeatRequest) ClaimIssueResult {
+	if req.IssueID.IsEmpty() {
+		return handleError(uc.logger, req.Agent, nil, &domain.ClaimFailed{
+			Agent:      &req.Agent,
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    "issue id is required",
+			OccurredAt: uc.clock.Now(),
+		})
+	}
+
+	issue, err := uc.repo.Heartbeat(ctx, req.Agent, req.IssueID)
+	if err != nil {
+		return handleError(uc.logger, req.Agent, nil, err)
+	}
+
+	uc.logger.Debug("heartbeat_recorded", map[string]interface{}{
+		"agent":    req.Agent.String(),
+		"issue_id": issue.ID.String(),
+	})
+
+	return ClaimIssueResult{
+		Status: "ok",
+		Agent:  req.Agent.String(),
+		Issue:  IssueToDTO(issue),
+	}
+}
+
+// ListAgentIssuesUseCase lists the issues an agent is working on.
+type ListAgentIssuesUseCase struct {
+	repo   IssueRepositoryPort
+	logger LoggerPort
+}
+
+// NewListAgentIssuesUseCase creates a new ListAgentIssuesUseCase.
+func NewListAgentIssuesUseCase(
+	repo IssueRepositoryPort,
+	logger LoggerPort,
+) *ListAgentIssuesUseCase {
+	return &ListAgentIssuesUseCase{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Execute lists the agent's in-progress issues, most urgent first.
+func (uc *ListAgentIssuesUseCase) Execute(ctx context.Context, req ListAgentIssuesRequest) ClaimIssueResult {
+	issues, err := uc.repo.FindIssuesByAssignee(ctx, req.Agent)
+	if err != nil {
+		return handleError(uc.logger, req.Agent, nil, err)
+	}
+
+	uc.logger.Debug("agent_issues_listed", map[string]interface{}{
+		"agent": req.Agent.String(),
+		"count": len(issues),
+	})
+
+	result := ClaimIssueResult{
+		Status: "ok",
+		Agent:  req.Agent.String(),
+	}
+	if len(issues) > 0 {
+		result.Issue = IssueToDTO(issues[0])
+		result.Issues = IssuesToDTO(issues)
+	}
+	return result
+}
+
+func handleError(logger LoggerPort, agent domain.AgentName, filters *FiltersDTO, err error) ClaimIssueResult {
 	var claimFailed *domain.ClaimFailed
 	if errors.As(err, &claimFailed) {
-		uc.logger.Error("claim_failed", map[string]interface{}{
+		logger.Error("claim_failed", map[string]interface{}{
 			"agent": agent.String(),
 			"code":  string(claimFailed.ErrorCode),
 			"error": claimFailed.Message,
@@ -101,7 +547,8 @@ func (uc *ClaimIssueUseCase) handleError(agent domain.AgentName, filters domain.
 			Status:  "error",
 			Agent:   agent.String(),
 			Issue:   nil,
-			Filters: FiltersToDTO(filters),
+			Issues:  IssuesToDTO(claimFailed.Issues),
+			Filters: filters,
 			Error: &ClaimErrorDTO{
 				Code:    string(claimFailed.ErrorCode),
 				Message: claimFailed.Message,
@@ -109,7 +556,7 @@ func (uc *ClaimIssueUseCase) handleError(agent domain.AgentName, filters domain.
 		}
 	}
 
-	uc.logger.Error("unexpected_error", map[string]interface{}{
+	logger.Error("unexpected_error", map[string]interface{}{
 		"agent": agent.String(),
 		"error": err.Error(),
 	})
@@ -117,7 +564,7 @@ func (uc *ClaimIssueUseCase) handleError(agent domain.AgentName, filters domain.
 		Status:  "error",
 		Agent:   agent.String(),
 		Issue:   nil,
-		Filters: FiltersToDTO(filters),
+		Filters: filters,
 		Error: &ClaimErrorDTO{
 			Code:    string(domain.ErrCodeUnexpected),
 			Message: err.Error(),
@@ -11,13 +11,50 @@ import (
 
 // MockIssueRepository is a mock implementation of IssueRepositoryPort.
 type MockIssueRepository struct {
-	ClaimFunc func(ctx context.Context, agent domain.AgentName, filters domain.ClaimFilters) (*domain.Issue, error)
-	FindFunc  func(ctx context.Context, filters domain.ClaimFilters) (*domain.Issue, error)
+	ClaimFunc     func(ctx context.Context, agent domain.AgentName, filters domain.ClaimFilters, opts domain.ClaimOptions) (*domain.Issue, error)
+	FindFunc      func(ctx context.Context, filters domain.ClaimFilters) (*domain.Issue, error)
+	ClaimManyFunc func(ctx context.Context, agent domain.AgentName, filters domain.ClaimFilters, n int, opts domain.ClaimOptions) ([]*domain.Issue, error)
+	FindManyFunc  func(ctx context.Context, filters domain.ClaimFilters, n int) ([]*domain.Issue, error)
+	ClaimByIDFunc func(ctx context.Context, agent domain.AgentName, issueID domain.IssueId, filters domain.ClaimFilters, opts domain.ClaimOptions) (*domain.Issue, error)
+	FindByIDFunc  func(ctx context.Context, issueID domain.IssueId, filters domain.ClaimFilters) (*domain.Issue, error)
+	ReleaseFunc   func(ctx context.Context, agent domain.AgentName, issueID domain.IssueId, reason string) (*domain.Issue, error)
+	HeartbeatFunc func(ctx context.Context, agent domain.AgentName, issueID domain.IssueId) (*domain.Issue, error)
+	AssignedFunc  func(ctx context.Context, agent domain.AgentName) ([]*domain.Issue, error)
+	CloseFunc     func(ctx context.Context, agent domain.AgentName, issueID domain.IssueId, reason string) (*domain.Issue, error)
+	TransferFunc  func(ctx context.Context, from, to domain.AgentName, issueID domain.IssueId, reason string) (*domain.Issue, error)
 }
 
-func (m *MockIssueRepository) ClaimOneReadyIssue(ctx context.Context, agent domain.AgentName, filters domain.ClaimFilters) (*domain.Issue, error) {
+func (m *MockIssueRepository) ClaimOneReadyIssue(ctx context.Context, agent domain.AgentName, filters domain.ClaimFilters, opts domain.ClaimOptions) (*domain.Issue, error) {
 	if m.ClaimFunc != nil {
-		return m.ClaimFunc(ctx, agent, filters)
+		return m.ClaimFunc(ctx, agent, filters, opts)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) ClaimReadyIssues(ctx context.Context, agent domain.AgentName, filters domain.ClaimFilters, n int, opts domain.ClaimOptions) ([]*domain.Issue, error) {
+	if m.ClaimManyFunc != nil {
+		return m.ClaimManyFunc(ctx, agent, filters, n, opts)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) ClaimIssueByID(ctx context.Context, agent domain.AgentName, issueID domain.IssueId, filters domain.ClaimFilters, opts domain.ClaimOptions) (*domain.Issue, error) {
+	if m.ClaimByIDFunc != nil {
+		return m.ClaimByIDFunc(ctx, agent, issueID, filters, opts)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) FindIssueByID(ctx context.Context, issueID domain.IssueId, filters domain.ClaimFilters) (*domain.Issue, error) {
+	if m.FindByIDFunc != nil {
+		return m.FindByIDFunc(ctx, issueID, filters)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) FindReadyIssues(ctx context.Context, filters domain.ClaimFilters, n int) ([]*domain.Issue, error) {
+	if m.FindManyFunc != nil {
+		return m.FindManyFunc(ctx, filters, n)
 	}
 	return nil, nil
 }
@@ -29,6 +66,41 @@ func (m *MockIssueRepository) FindOneReadyIssue(ctx context.Context, filters dom
 	return nil, nil
 }
 
+func (m *MockIssueRepository) ReleaseIssue(ctx context.Context, agent domain.AgentName, issueID domain.IssueId, reason string) (*domain.Issue, error) {
+	if m.ReleaseFunc != nil {
+		return m.ReleaseFunc(ctx, agent, issueID, reason)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) Heartbeat(ctx context.Context, agent domain.AgentName, issueID domain.IssueId) (*domain.Issue, error) {
+	if m.HeartbeatFunc != nil {
+		return m.HeartbeatFunc(ctx, agent, issueID)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) FindIssuesByAssignee(ctx context.Context, agent domain.AgentName) ([]*domain.Issue, error) {
+	if m.AssignedFunc != nil {
+		return m.AssignedFunc(ctx, agent)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) CloseIssue(ctx context.Context, agent domain.AgentName, issueID domain.IssueId, reason string) (*domain.Issue, error) {
+	if m.CloseFunc != nil {
+		return m.CloseFunc(ctx, agent, issueID, reason)
+	}
+	return nil, nil
+}
+
+func (m *MockIssueRepository) TransferIssue(ctx context.Context, from, to domain.AgentName, issueID domain.IssueId, reason string) (*domain.Issue, error) {
+	if m.TransferFunc != nil {
+		return m.TransferFunc(ctx, from, to, issueID, reason)
+	}
+	return nil, nil
+}
+
 // MockClock is a mock implementation of ClockPort.
 type MockClock struct {
 	now domain.Timestamp
@@ -73,7 +145,7 @@ func TestClaimIssueUseCase_Execute_Success(t *testing.T) {
 	}
 
 	repo := &MockIssueRepository{
-		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters) (*domain.Issue, error) {
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
 			return issue, nil
 		},
 	}
@@ -104,7 +176,7 @@ func TestClaimIssueUseCase_Execute_NoIssue(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 
 	repo := &MockIssueRepository{
-		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters) (*domain.Issue, error) {
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
 			return nil, nil
 		},
 	}
@@ -196,7 +268,7 @@ func TestClaimIssueUseCase_Execute_ClaimError(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 
 	repo := &MockIssueRepository{
-		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters) (*domain.Issue, error) {
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
 			return nil, &domain.ClaimFailed{
 				ErrorCode:  domain.ErrCodeSQLiteBusy,
 				Message:    "database is busy",
@@ -227,11 +299,46 @@ func TestClaimIssueUseCase_Execute_ClaimError(t *testing.T) {
 	}
 }
 
+func TestClaimIssueUseCase_Execute_WIPLimitReached(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			if o.MaxInProgress != 1 {
+				t.Errorf("expected the limit to reach the repository, got %d", o.MaxInProgress)
+			}
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeWIPLimitReached,
+				Message:    "agent test-agent already has 1 issues in progress (limit 1)",
+				OccurredAt: domain.Now(),
+				Issues:     []*domain.Issue{{ID: "held-1", Status: domain.StatusInProgress, Assignee: &a}},
+			}
+		},
+	}
+
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   agent,
+		Filters: domain.NewClaimFilters(),
+		Options: domain.ClaimOptions{MaxInProgress: 1},
+	})
+
+	if result.Error == nil || result.Error.Code != "WIP_LIMIT_REACHED" {
+		t.Fatalf("expected WIP_LIMIT_REACHED error, got %+v", result.Error)
+	}
+	if result.Issue != nil {
+		t.Errorf("expected no claimed issue, got %+v", result.Issue)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].ID != "held-1" {
+		t.Errorf("expected the agent's current issues, got %+v", result.Issues)
+	}
+}
+
 func TestClaimIssueUseCase_Execute_UnexpectedError(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 
 	repo := &MockIssueRepository{
-		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters) (*domain.Issue, error) {
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
 			return nil, errors.New("unexpected error")
 		},
 	}
@@ -292,3 +399,554 @@ func TestClaimIssueUseCase_Execute_DryRunError(t *testing.T) {
 		t.Errorf("expected error code 'DB_NOT_FOUND', got '%s'", result.Error.Code)
 	}
 }
+
+func TestClaimIssueUseCase_Execute_Batch(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	var gotN int
+	repo := &MockIssueRepository{
+		ClaimManyFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, n int, o domain.ClaimOptions) ([]*domain.Issue, error) {
+			gotN = n
+			return []*domain.Issue{
+				{ID: "test-1", Status: domain.StatusInProgress, Assignee: &a},
+				{ID: "test-2", Status: domain.StatusInProgress, Assignee: &a},
+			}, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   agent,
+		Filters: domain.NewClaimFilters(),
+		Count:   3,
+	})
+
+	if result.Status != "ok" {
+		t.Fatalf("expected status 'ok', got '%s'", result.Status)
+	}
+	if gotN != 3 {
+		t.Errorf("expected batch of 3 to be requested, got %d", gotN)
+	}
+	if len(result.Issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(result.Issues))
+	}
+	if result.Issue == nil || result.Issue.ID != "test-1" {
+		t.Errorf("expected issue to be the first of the batch, got %+v", result.Issue)
+	}
+}
+
+func TestClaimIssueUseCase_Execute_BatchDryRun(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		FindManyFunc: func(ctx context.Context, f domain.ClaimFilters, n int) ([]*domain.Issue, error) {
+			return []*domain.Issue{{ID: "test-1", Status: domain.StatusOpen}, {ID: "test-2", Status: domain.StatusOpen}}, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   agent,
+		Filters: domain.NewClaimFilters(),
+		Count:   2,
+		DryRun:  true,
+	})
+
+	if len(result.Issues) != 2 || result.Issue.ID != "test-1" {
+		t.Errorf("unexpected dry-run result: %+v", result)
+	}
+}
+
+func TestClaimIssueUseCase_Execute_InvalidCount(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	useCase := NewClaimIssueUseCase(&MockIssueRepository{}, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   agent,
+		Filters: domain.NewClaimFilters(),
+		Count:   -1,
+	})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestClaimIssueUseCase_Execute_ByID(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	var gotID domain.IssueId
+	repo := &MockIssueRepository{
+		ClaimByIDFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			gotID = id
+			return &domain.Issue{ID: id, Status: domain.StatusInProgress, Assignee: &a}, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   agent,
+		Filters: domain.NewClaimFilters(),
+		IssueID: "test-123",
+	})
+
+	if result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-123" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if gotID != "test-123" {
+		t.Errorf("expected test-123 to be claimed, got %s", gotID)
+	}
+}
+
+func TestClaimIssueUseCase_Execute_ByIDErrors(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	for _, code := range []domain.ClaimErrorCode{domain.ErrCodeNotFound, domain.ErrCodeAlreadyClaimed, domain.ErrCodeNotReady} {
+		t.Run(string(code), func(t *testing.T) {
+			repo := &MockIssueRepository{
+				ClaimByIDFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+					return nil, &domain.ClaimFailed{ErrorCode: code, Message: "nope", OccurredAt: domain.Now()}
+				},
+				FindByIDFunc: func(ctx context.Context, id domain.IssueId, f domain.ClaimFilters) (*domain.Issue, error) {
+					return nil, &domain.ClaimFailed{ErrorCode: code, Message: "nope", OccurredAt: domain.Now()}
+				},
+			}
+			useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+			for _, dryRun := range []bool{false, true} {
+				result := useCase.Execute(context.Background(), ClaimIssueRequest{
+					Agent:   agent,
+					Filters: domain.NewClaimFilters(),
+					IssueID: "test-123",
+					DryRun:  dryRun,
+				})
+				if result.Error == nil || result.Error.Code != string(code) {
+					t.Errorf("dry-run=%v: expected %s error, got %+v", dryRun, code, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestClaimIssueUseCase_Execute_ByIDWithCount(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	useCase := NewClaimIssueUseCase(&MockIssueRepository{}, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   agent,
+		Filters: domain.NewClaimFilters(),
+		IssueID: "test-123",
+		Count:   2,
+	})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestClaimIssueUseCase_Execute_PassesOptions(t *testing.T) {
+	var got domain.ClaimOptions
+	repo := &MockIssueRepository{
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			got = o
+			return nil, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   "test-agent",
+		Filters: domain.NewClaimFilters(),
+		Options: domain.ClaimOptions{Lease: time.Hour},
+	})
+
+	if result.Status != "ok" {
+		t.Errorf("expected status 'ok', got '%s'", result.Status)
+	}
+	if got.Lease != time.Hour {
+		t.Errorf("expected lease to reach the repository, got %s", got.Lease)
+	}
+}
+
+func TestClaimIssueUseCase_Execute_InvalidOptions(t *testing.T) {
+	repo := &MockIssueRepository{
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			t.Error("repository should not be called with invalid options")
+			return nil, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.Execute(context.Background(), ClaimIssueRequest{
+		Agent:   "test-agent",
+		Filters: domain.NewClaimFilters(),
+		Options: domain.ClaimOptions{Lease: -time.Hour},
+	})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestReleaseIssueUseCase_Execute_Success(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	var gotReason string
+	repo := &MockIssueRepository{
+		ReleaseFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId, reason string) (*domain.Issue, error) {
+			gotReason = reason
+			return &domain.Issue{ID: id, Status: domain.StatusOpen}, nil
+		},
+	}
+	clock := &MockClock{now: domain.Now()}
+	logger := &MockLogger{}
+
+	useCase := NewReleaseIssueUseCase(repo, clock, logger)
+	result := useCase.Execute(context.Background(), ReleaseIssueRequest{
+		Agent:   agent,
+		IssueID: "test-123",
+		Reason:  "cannot reproduce",
+	})
+
+	if result.Status != "ok" {
+		t.Errorf("expected status 'ok', got '%s'", result.Status)
+	}
+	if result.Issue == nil || result.Issue.ID != "test-123" || result.Issue.Status != "open" {
+		t.Errorf("unexpected issue: %+v", result.Issue)
+	}
+	if result.Filters != nil {
+		t.Error("expected no filters in release result")
+	}
+	if gotReason != "cannot reproduce" {
+		t.Errorf("expected reason to be passed through, got %q", gotReason)
+	}
+}
+
+func TestReleaseIssueUseCase_Execute_MissingIssueID(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	useCase := NewReleaseIssueUseCase(&MockIssueRepository{}, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), ReleaseIssueRequest{Agent: agent})
+
+	if result.Status != "error" {
+		t.Errorf("expected status 'error', got '%s'", result.Status)
+	}
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestReleaseIssueUseCase_Execute_NotOwner(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		ReleaseFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId, reason string) (*domain.Issue, error) {
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeNotOwner,
+				Message:    "issue is not assigned to test-agent",
+				OccurredAt: domain.Now(),
+			}
+		},
+	}
+
+	useCase := NewReleaseIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), ReleaseIssueRequest{Agent: agent, IssueID: "test-123"})
+
+	if result.Status != "error" {
+		t.Errorf("expected status 'error', got '%s'", result.Status)
+	}
+	if result.Error == nil || result.Error.Code != "NOT_OWNER" {
+		t.Errorf("expected NOT_OWNER error, got %+v", result.Error)
+	}
+}
+
+func TestCompleteIssueUseCase_Execute_Success(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		CloseFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId, reason string) (*domain.Issue, error) {
+			if reason != "shipped" {
+				t.Errorf("expected reason to be passed through, got %q", reason)
+			}
+			issue := &domain.Issue{ID: id, Status: domain.StatusInProgress, Assignee: &a}
+			issue.Close(a, reason, time.Now())
+			return issue, nil
+		},
+	}
+
+	useCase := NewCompleteIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), CompleteIssueRequest{Agent: agent, IssueID: "test-123", Reason: "shipped"})
+
+	if result.Status != "ok" || result.Issue == nil {
+		t.Fatalf("expected closed issue, got %+v", result)
+	}
+	if result.Issue.Status != "closed" || result.Issue.ClosedAt == nil {
+		t.Errorf("expected closed status and closed_at, got %+v", result.Issue)
+	}
+}
+
+func TestCompleteIssueUseCase_Execute_Errors(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		CloseFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId, reason string) (*domain.Issue, error) {
+			return nil, &domain.ClaimFailed{ErrorCode: domain.ErrCodeNotOwner, Message: "not yours", OccurredAt: domain.Now()}
+		},
+	}
+	useCase := NewCompleteIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.Execute(context.Background(), CompleteIssueRequest{Agent: agent})
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+
+	result = useCase.Execute(context.Background(), CompleteIssueRequest{Agent: agent, IssueID: "test-123"})
+	if result.Error == nil || result.Error.Code != "NOT_OWNER" {
+		t.Errorf("expected NOT_OWNER error, got %+v", result.Error)
+	}
+}
+
+func TestTransferIssueUseCase_Execute_Success(t *testing.T) {
+	from, _ := domain.NewAgentName("planner")
+	to, _ := domain.NewAgentName("specialist")
+
+	repo := &MockIssueRepository{
+		TransferFunc: func(ctx context.Context, f, t domain.AgentName, id domain.IssueId, reason string) (*domain.Issue, error) {
+			issue := &domain.Issue{ID: id, Status: domain.StatusInProgress, Assignee: &f}
+			issue.Transfer(f, t, reason, time.Now())
+			return issue, nil
+		},
+	}
+
+	useCase := NewTransferIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), TransferIssueRequest{Agent: from, To: to, IssueID: "test-123"})
+
+	if result.Status != "ok" || result.Issue == nil {
+		t.Fatalf("expected transferred issue, got %+v", result)
+	}
+	if result.Agent != "planner" {
+		t.Errorf("expected result agent 'planner', got '%s'", result.Agent)
+	}
+	if result.Issue.Assignee == nil || *result.Issue.Assignee != "specialist" {
+		t.Errorf("expected assignee 'specialist', got %v", result.Issue.Assignee)
+	}
+	if result.Issue.TransferredFrom == nil || *result.Issue.TransferredFrom != "planner" {
+		t.Errorf("expected transferred_from 'planner', got %v", result.Issue.TransferredFrom)
+	}
+	if result.Issue.Status != "in_progress" {
+		t.Errorf("expected status to stay 'in_progress', got '%s'", result.Issue.Status)
+	}
+}
+
+func TestTransferIssueUseCase_Execute_Errors(t *testing.T) {
+	from, _ := domain.NewAgentName("planner")
+	to, _ := domain.NewAgentName("specialist")
+
+	repo := &MockIssueRepository{
+		TransferFunc: func(ctx context.Context, f, t domain.AgentName, id domain.IssueId, reason string) (*domain.Issue, error) {
+			return nil, &domain.ClaimFailed{ErrorCode: domain.ErrCodeNotOwner, Message: "not yours", OccurredAt: domain.Now()}
+		},
+	}
+	useCase := NewTransferIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	tests := []struct {
+		name string
+		req  TransferIssueRequest
+		code string
+	}{
+		{"missing issue", TransferIssueRequest{Agent: from, To: to}, "INVALID_ARGUMENT"},
+		{"self transfer", TransferIssueRequest{Agent: from, To: from, IssueID: "test-123"}, "INVALID_ARGUMENT"},
+		{"not owner", TransferIssueRequest{Agent: from, To: to, IssueID: "test-123"}, "NOT_OWNER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := useCase.Execute(context.Background(), tt.req)
+			if result.Error == nil || result.Error.Code != tt.code {
+				t.Errorf("expected %s error, got %+v", tt.code, result.Error)
+			}
+		})
+	}
+}
+
+func TestHeartbeatUseCase_Execute_Success(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+	now := time.Now()
+
+	repo := &MockIssueRepository{
+		HeartbeatFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId) (*domain.Issue, error) {
+			return &domain.Issue{ID: id, Status: domain.StatusInProgress, Assignee: &a, LastHeartbeatAt: &now}, nil
+		},
+	}
+
+	useCase := NewHeartbeatUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), HeartbeatRequest{Agent: agent, IssueID: "test-123"})
+
+	if result.Status != "ok" {
+		t.Errorf("expected status 'ok', got '%s'", result.Status)
+	}
+	if result.Issue == nil || result.Issue.LastHeartbeatAt == nil {
+		t.Fatalf("expected issue with last_heartbeat_at, got %+v", result.Issue)
+	}
+}
+
+func TestHeartbeatUseCase_Execute_MissingIssueID(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	useCase := NewHeartbeatUseCase(&MockIssueRepository{}, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), HeartbeatRequest{Agent: agent})
+
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestHeartbeatUseCase_Execute_LeaseLost(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		HeartbeatFunc: func(ctx context.Context, a domain.AgentName, id domain.IssueId) (*domain.Issue, error) {
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeLeaseLost,
+				Message:    "issue test-123 is no longer claimed by test-agent; stop work",
+				OccurredAt: domain.Now(),
+			}
+		},
+	}
+
+	useCase := NewHeartbeatUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	result := useCase.Execute(context.Background(), HeartbeatRequest{Agent: agent, IssueID: "test-123"})
+
+	if result.Error == nil || result.Error.Code != "LEASE_LOST" {
+		t.Errorf("expected LEASE_LOST error, got %+v", result.Error)
+	}
+}
+
+func TestListAgentIssuesUseCase_Execute(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	repo := &MockIssueRepository{
+		AssignedFunc: func(ctx context.Context, a domain.AgentName) ([]*domain.Issue, error) {
+			if a != agent {
+				t.Errorf("expected %s, got %s", agent, a)
+			}
+			return []*domain.Issue{
+				{ID: "test-1", Status: domain.StatusInProgress, Assignee: &a},
+				{ID: "test-2", Status: domain.StatusInProgress, Assignee: &a},
+			}, nil
+		},
+	}
+
+	useCase := NewListAgentIssuesUseCase(repo, &MockLogger{})
+	result := useCase.Execute(context.Background(), ListAgentIssuesRequest{Agent: agent})
+
+	if result.Status != "ok" || len(result.Issues) != 2 || result.Issue.ID != "test-1" {
+		t.Errorf("expected both issues, got %+v", result)
+	}
+
+	empty := NewListAgentIssuesUseCase(&MockIssueRepository{}, &MockLogger{})
+	result = empty.Execute(context.Background(), ListAgentIssuesRequest{Agent: agent})
+	if result.Status != "ok" || result.Issue != nil || len(result.Issues) != 0 {
+		t.Errorf("expected no issues, got %+v", result)
+	}
+}
+
+func TestClaimIssueUseCase_ExecuteWait_ClaimsEventually(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+
+	attempts := 0
+	repo := &MockIssueRepository{
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			attempts++
+			switch attempts {
+			case 1:
+				return nil, nil
+			case 2:
+				return nil, &domain.ClaimFailed{ErrorCode: domain.ErrCodeSQLiteBusy, Message: "busy", OccurredAt: domain.Now()}
+			}
+			return &domain.Issue{ID: "test-123", Status: domain.StatusInProgress, Assignee: &a}, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+
+	result := useCase.ExecuteWait(context.Background(), ClaimIssueRequest{Agent: agent, Filters: domain.NewClaimFilters()}, WaitOptions{PollInterval: time.Millisecond})
+
+	if result.Status != "ok" || result.Issue == nil || result.Issue.ID != "test-123" {
+		t.Fatalf("expected test-123 to be claimed, got %+v", result)
+	}
+	if result.Wait == nil || result.Wait.Outcome != WaitOutcomeClaimed || result.Wait.Attempts != 3 {
+		t.Errorf("unexpected wait report: %+v", result.Wait)
+	}
+}
+
+func TestClaimIssueUseCase_ExecuteWait_Stops(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+	repo := &MockIssueRepository{
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			return nil, nil
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	req := ClaimIssueRequest{Agent: agent, Filters: domain.NewClaimFilters()}
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		result := useCase.ExecuteWait(ctx, req, WaitOptions{PollInterval: 5 * time.Millisecond})
+
+		if result.Status != "ok" || result.Issue != nil {
+			t.Errorf("expected empty ok result, got %+v", result)
+		}
+		if result.Wait == nil || result.Wait.Outcome != WaitOutcomeTimeout || result.Wait.Attempts < 2 {
+			t.Errorf("unexpected wait report: %+v", result.Wait)
+		}
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		result := useCase.ExecuteWait(ctx, req, WaitOptions{PollInterval: time.Hour})
+
+		if result.Wait == nil || result.Wait.Outcome != WaitOutcomeInterrupted {
+			t.Errorf("unexpected wait report: %+v", result.Wait)
+		}
+	})
+}
+
+func TestClaimIssueUseCase_ExecuteWait_Errors(t *testing.T) {
+	agent, _ := domain.NewAgentName("test-agent")
+	repo := &MockIssueRepository{
+		ClaimFunc: func(ctx context.Context, a domain.AgentName, f domain.ClaimFilters, o domain.ClaimOptions) (*domain.Issue, error) {
+			return nil, &domain.ClaimFailed{ErrorCode: domain.ErrCodeSchemaIncompatible, Message: "old schema", OccurredAt: domain.Now()}
+		},
+	}
+	useCase := NewClaimIssueUseCase(repo, &MockClock{now: domain.Now()}, &MockLogger{})
+	req := ClaimIssueRequest{Agent: agent, Filters: domain.NewClaimFilters()}
+
+	result := useCase.ExecuteWait(context.Background(), req, WaitOptions{PollInterval: time.Millisecond})
+	if result.Error == nil || result.Error.Code != "SCHEMA_INCOMPATIBLE" {
+		t.Errorf("expected SCHEMA_INCOMPATIBLE error, got %+v", result.Error)
+	}
+	if result.Wait == nil || result.Wait.Outcome != WaitOutcomeError || result.Wait.Attempts != 1 {
+		t.Errorf("unexpected wait report: %+v", result.Wait)
+	}
+
+	result = useCase.ExecuteWait(context.Background(), req, WaitOptions{})
+	if result.Error == nil || result.Error.Code != "INVALID_ARGUMENT" {
+		t.Errorf("expected INVALID_ARGUMENT error, got %+v", result.Error)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second)
+		if d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("jitter out of range: %s", d)
+		}
+	}
+	if jitter(time.Nanosecond) != time.Nanosecond {
+		t.Error("expected tiny intervals to be left alone")
+	}
+}
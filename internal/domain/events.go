@@ -7,6 +7,41 @@ type IssueClaimed struct {
 	ClaimedAt Timestamp
 }
 
+// IssueReclaimed is emitted when an agent claims an issue whose previous
+// claim lease expired.
+type IssueReclaimed struct {
+	IssueID       IssueId
+	Agent         AgentName
+	PreviousAgent AgentName
+	ReclaimedAt   Timestamp
+}
+
+// IssueReleased is emitted when an agent gives a claimed issue back to the ready pool.
+type IssueReleased struct {
+	IssueID    IssueId
+	Agent      AgentName
+	Reason     string
+	ReleasedAt Timestamp
+}
+
+// IssueClosed is emitted when an agent finishes its claimed issue.
+type IssueClosed struct {
+	IssueID  IssueId
+	Agent    AgentName
+	Reason   string
+	ClosedAt Timestamp
+}
+
+// IssueTransferred is emitted when the agent holding an issue hands it
+// straight to another agent.
+type IssueTransferred struct {
+	IssueID       IssueId
+	From          AgentName
+	To            AgentName
+	Reason        string
+	TransferredAt Timestamp
+}
+
 // NoIssueAvailable is emitted when no eligible issue is found.
 type NoIssueAvailable struct {
 	Agent     AgentName
@@ -18,12 +53,21 @@ type NoIssueAvailable struct {
 type ClaimErrorCode string
 
 const (
-	ErrCodeDBNotFound          ClaimErrorCode = "DB_NOT_FOUND"
-	ErrCodeSchemaIncompatible  ClaimErrorCode = "SCHEMA_INCOMPATIBLE"
-	ErrCodeSQLiteBusy          ClaimErrorCode = "SQLITE_BUSY"
-	ErrCodeWorkspaceNotFound   ClaimErrorCode = "WORKSPACE_NOT_FOUND"
-	ErrCodeInvalidArgument     ClaimErrorCode = "INVALID_ARGUMENT"
-	ErrCodeUnexpected          ClaimErrorCode = "UNEXPECTED"
+	ErrCodeDBNotFound         ClaimErrorCode = "DB_NOT_FOUND"
+	ErrCodeSchemaIncompatible ClaimErrorCode = "SCHEMA_INCOMPATIBLE"
+	ErrCodeSQLiteBusy         ClaimErrorCode = "SQLITE_BUSY"
+	ErrCodeWorkspaceNotFound  ClaimErrorCode = "WORKSPACE_NOT_FOUND"
+	ErrCodeInvalidArgument    ClaimErrorCode = "INVALID_ARGUMENT"
+	ErrCodeUnexpected         ClaimErrorCode = "UNEXPECTED"
+	ErrCodeNotFound           ClaimErrorCode = "NOT_FOUND"
+	ErrCodeNotOwner           ClaimErrorCode = "NOT_OWNER"
+	ErrCodeLeaseLost          ClaimErrorCode = "LEASE_LOST"
+	ErrCodeNotReady           ClaimErrorCode = "NOT_READY"
+	ErrCodeAlreadyClaimed     ClaimErrorCode = "ALREADY_CLAIMED"
+	ErrCodeAlreadyClosed      ClaimErrorCode = "ALREADY_CLOSED"
+	ErrCodeNotClaimed         ClaimErrorCode = "NOT_CLAIMED"
+	ErrCodeWIPLimitReached    ClaimErrorCode = "WIP_LIMIT_REACHED"
+	ErrCodeUnauthorized       ClaimErrorCode = "UNAUTHORIZED"
 )
 
 // ClaimFailed is emitted when a claim attempt fails due to technical reasons.
@@ -32,6 +76,10 @@ type ClaimFailed struct {
 	ErrorCode  ClaimErrorCode
 	Message    string
 	OccurredAt Timestamp
+
+	// Issues lists the issues behind the failure, such as the agent's work
+	// in progress when WIP_LIMIT_REACHED stops a claim.
+	Issues []*Issue
 }
 
 // Error implements the error interface for ClaimFailed.
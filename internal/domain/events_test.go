@@ -29,6 +29,11 @@ func TestErrorCodes(t *testing.T) {
 		ErrCodeWorkspaceNotFound,
 		ErrCodeInvalidArgument,
 		ErrCodeUnexpected,
+		ErrCodeNotFound,
+		ErrCodeNotOwner,
+		ErrCodeLeaseLost,
+		ErrCodeNotReady,
+		ErrCodeAlreadyClaimed,
 	}
 
 	for _, code := range codes {
@@ -0,0 +1,276 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxLabelExprNodes bounds an expression's size so its SQL translation stays
+// well within SQLite's expression depth limit.
+const maxLabelExprNodes = 100
+
+// LabelExpr is a boolean expression over an issue's labels, such as
+// "label:frontend or label:docs and not label:blocked-external".
+//
+// The repository translates expressions to SQL, so the node types are
+// exported and closed: LabelTerm, NotExpr, AndExpr and OrExpr.
+type LabelExpr interface {
+	// Match reports whether an issue with these labels satisfies the expression.
+	Match(labels LabelSet) bool
+	// String renders the expression in the syntax ParseLabelExpr reads.
+	String() string
+
+	isLabelExpr()
+}
+
+// LabelTerm matches issues carrying Label.
+type LabelTerm struct {
+	Label string
+}
+
+// NotExpr matches issues X does not match.
+type NotExpr struct {
+	X LabelExpr
+}
+
+// AndExpr matches issues both X and Y match.
+type AndExpr struct {
+	X, Y LabelExpr
+}
+
+// OrExpr matches issues either X or Y matches.
+type OrExpr struct {
+	X, Y LabelExpr
+}
+
+func (LabelTerm) isLabelExpr() {}
+func (NotExpr) isLabelExpr()   {}
+func (AndExpr) isLabelExpr()   {}
+func (OrExpr) isLabelExpr()    {}
+
+// Match implements LabelExpr.
+func (e LabelTerm) Match(labels LabelSet) bool { return labels.Contains(e.Label) }
+
+// Match implements LabelExpr.
+func (e NotExpr) Match(labels LabelSet) bool { return !e.X.Match(labels) }
+
+// Match implements LabelExpr.
+func (e AndExpr) Match(labels LabelSet) bool { return e.X.Match(labels) && e.Y.Match(labels) }
+
+// Match implements LabelExpr.
+func (e OrExpr) Match(labels LabelSet) bool { return e.X.Match(labels) || e.Y.Match(labels) }
+
+// String implements LabelExpr. Labels that would not read back as one word
+// are quoted.
+func (e LabelTerm) String() string {
+	if e.Label == "" || strings.ContainsFunc(e.Label, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+	}) {
+		return "label:" + strconv.Quote(e.Label)
+	}
+	return "label:" + e.Label
+}
+
+// String implements LabelExpr.
+func (e NotExpr) String() string {
+	if _, ok := e.X.(LabelTerm); ok {
+		return "not " + e.X.String()
+	}
+	if _, ok := e.X.(NotExpr); ok {
+		return "not " + e.X.String()
+	}
+	return "not (" + e.X.String() + ")"
+}
+
+// String implements LabelExpr.
+func (e AndExpr) String() string {
+	return parenthesizeOr(e.X) + " and " + parenthesizeOr(e.Y)
+}
+
+// String implements LabelExpr.
+func (e OrExpr) String() string {
+	return e.X.String() + " or " + e.Y.String()
+}
+
+func parenthesizeOr(e LabelExpr) string {
+	if _, ok := e.(OrExpr); ok {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// ParseLabelExpr parses a label expression. Terms are written label:<name>,
+// with the name in double quotes if it contains spaces or parentheses. They
+// combine with not, and and or, binding in that order, and parentheses group.
+func ParseLabelExpr(s string) (LabelExpr, error) {
+	tokens, err := tokenizeLabelExpr(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", s, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid expression %q: empty", s)
+	}
+
+	p := &labelExprParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", s, err)
+	}
+	return expr, nil
+}
+
+// tokenizeLabelExpr splits s into parentheses and words. A double-quoted
+// section belongs to the word it appears in.
+func tokenizeLabelExpr(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n\r()", rune(s[i])) {
+				if s[i] != '"' {
+					i++
+					continue
+				}
+				end := i + 1
+				for end < len(s) && s[end] != '"' {
+					if s[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				if end >= len(s) {
+					return nil, errors.New("unterminated quote")
+				}
+				i = end + 1
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+type labelExprParser struct {
+	tokens []string
+	pos    int
+	nodes  int
+}
+
+// node counts a term, operator or group, failing once there are too many.
+func (p *labelExprParser) node() error {
+	p.nodes++
+	if p.nodes > maxLabelExprNodes {
+		return fmt.Errorf("more than %d terms, operators and groups", maxLabelExprNodes)
+	}
+	return nil
+}
+
+// keyword reports whether the next token is the keyword kw, consuming it if so.
+func (p *labelExprParser) keyword(kw string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *labelExprParser) parseOr() (LabelExpr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		if err := p.node(); err != nil {
+			return nil, err
+		}
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = OrExpr{X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *labelExprParser) parseAnd() (LabelExpr, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		if err := p.node(); err != nil {
+			return nil, err
+		}
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = AndExpr{X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *labelExprParser) parseNot() (LabelExpr, error) {
+	if p.keyword("not") {
+		if err := p.node(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{X: x}, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *labelExprParser) parseTerm() (LabelExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	if err := p.node(); err != nil {
+		return nil, err
+	}
+
+	if token == "(" {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return x, nil
+	}
+
+	key, value, ok := strings.Cut(token, ":")
+	if !ok || !strings.EqualFold(key, "label") {
+		return nil, fmt.Errorf("expected label:<name>, got %q", token)
+	}
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted label %s", value)
+		}
+		value = unquoted
+	}
+	if value == "" {
+		return nil, fmt.Errorf("label name cannot be empty in %q", token)
+	}
+
+	return LabelTerm{Label: value}, nil
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelExpr(t *testing.T) {
+	fe := LabelTerm{Label: "frontend"}
+	docs := LabelTerm{Label: "docs"}
+	blocked := LabelTerm{Label: "blocked-external"}
+
+	tests := []struct {
+		input string
+		want  LabelExpr
+	}{
+		{"label:frontend", fe},
+		{"label:component:api", LabelTerm{Label: "component:api"}},
+		{`label:"needs review"`, LabelTerm{Label: "needs review"}},
+		{"not label:frontend", NotExpr{X: fe}},
+		{"label:frontend or label:docs", OrExpr{X: fe, Y: docs}},
+		// and binds tighter than or, not tighter than and
+		{
+			"label:frontend or label:docs and not label:blocked-external",
+			OrExpr{X: fe, Y: AndExpr{X: docs, Y: NotExpr{X: blocked}}},
+		},
+		{
+			"(label:frontend OR label:docs) AND NOT label:blocked-external",
+			AndExpr{X: OrExpr{X: fe, Y: docs}, Y: NotExpr{X: blocked}},
+		},
+		{"not (label:frontend and label:docs)", NotExpr{X: AndExpr{X: fe, Y: docs}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLabelExpr(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+
+			// String renders an expression that parses back to the same tree
+			again, err := ParseLabelExpr(got.String())
+			if err != nil {
+				t.Fatalf("failed to reparse %q: %v", got.String(), err)
+			}
+			if !reflect.DeepEqual(again, got) {
+				t.Errorf("round trip through %q changed the expression", got.String())
+			}
+		})
+	}
+}
+
+func TestParseLabelExpr_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "empty"},
+		{"frontend", "expected label:<name>"},
+		{"priority:P1", "expected label:<name>"},
+		{"label:", "cannot be empty"},
+		{"label:frontend or", "unexpected end"},
+		{"label:frontend label:docs", "unexpected"},
+		{"(label:frontend", "missing )"},
+		{"label:frontend)", "unexpected \")\""},
+		{`label:"frontend`, "unterminated quote"},
+		{strings.Repeat("not ", 100) + "label:frontend", "more than 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseLabelExpr(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLabelExpr_Match(t *testing.T) {
+	expr, err := ParseLabelExpr("label:frontend or label:docs and not label:blocked-external")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		labels LabelSet
+		want   bool
+	}{
+		{LabelSet{"frontend"}, true},
+		{LabelSet{"frontend", "blocked-external"}, true},
+		{LabelSet{"docs"}, true},
+		{LabelSet{"docs", "blocked-external"}, false},
+		{LabelSet{"backend"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := expr.Match(tt.labels); got != tt.want {
+			t.Errorf("Match(%v) = %v, expected %v", tt.labels, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Issue represents an issue aggregate in the claiming context.
 type Issue struct {
@@ -15,6 +18,20 @@ type Issue struct {
 	Blocked     bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	// ClosedAt is when the issue was closed; nil while it is not.
+	ClosedAt *time.Time
+	// LeaseExpiresAt is when the current claim lapses; nil if it never does.
+	LeaseExpiresAt *time.Time
+	// LastHeartbeatAt is when the claim holder last reported it was alive.
+	LastHeartbeatAt *time.Time
+	// ReclaimedFrom names the agent whose expired lease this claim took over.
+	ReclaimedFrom *AgentName
+	// TransferredFrom names the agent that handed this issue over.
+	TransferredFrom *AgentName
+	// Unblocks counts the unfinished issues waiting on this one, directly or
+	// transitively. It is only computed under StrategyCriticalPath.
+	Unblocks *int
 }
 
 // IsReady returns true if the issue is eligible for claiming.
@@ -43,10 +60,26 @@ func (i *Issue) CanBeClaimed(filters ClaimFilters) bool {
 		return false
 	}
 
-	// Check minimum priority
+	// Check issue type
+	if len(filters.IncludeTypes) > 0 && !slices.Contains(filters.IncludeTypes, i.IssueType) {
+		return false
+	}
+	if slices.Contains(filters.ExcludeTypes, i.IssueType) {
+		return false
+	}
+
+	// Check label expression
+	if filters.Where != nil && !filters.Where.Match(i.Labels) {
+		return false
+	}
+
+	// Check priority range (lower numbers are more urgent)
 	if filters.MinPriority != nil && i.Priority < *filters.MinPriority {
 		return false
 	}
+	if filters.MaxPriority != nil && i.Priority > *filters.MaxPriority {
+		return false
+	}
 
 	return true
 }
@@ -63,3 +96,57 @@ func (i *Issue) Claim(agent AgentName, now time.Time) *IssueClaimed {
 		ClaimedAt: Timestamp(now),
 	}
 }
+
+// LeaseExpired returns true if the issue is held under a lease that lapsed before now.
+func (i *Issue) LeaseExpired(now time.Time) bool {
+	return i.Status == StatusInProgress && i.LeaseExpiresAt != nil && i.LeaseExpiresAt.Before(now)
+}
+
+// IsOwnedBy returns true if the issue is in progress and assigned to the agent.
+func (i *Issue) IsOwnedBy(agent AgentName) bool {
+	return i.Status == StatusInProgress && i.Assignee != nil && *i.Assignee == agent
+}
+
+// Release transitions a claimed issue back to the ready pool.
+func (i *Issue) Release(agent AgentName, reason string, now time.Time) *IssueReleased {
+	i.Status = StatusOpen
+	i.Assignee = nil
+	i.UpdatedAt = now
+
+	return &IssueReleased{
+		IssueID:    i.ID,
+		Agent:      agent,
+		Reason:     reason,
+		ReleasedAt: Timestamp(now),
+	}
+}
+
+// Close transitions a claimed issue to closed.
+func (i *Issue) Close(agent AgentName, reason string, now time.Time) *IssueClosed {
+	i.Status = StatusClosed
+	i.UpdatedAt = now
+	i.ClosedAt = &now
+
+	return &IssueClosed{
+		IssueID:  i.ID,
+		Agent:    agent,
+		Reason:   reason,
+		ClosedAt: Timestamp(now),
+	}
+}
+
+// Transfer reassigns a claimed issue to another agent. The issue stays in
+// progress throughout, so it is never up for grabs.
+func (i *Issue) Transfer(from, to AgentName, reason string, now time.Time) *IssueTransferred {
+	i.Assignee = &to
+	i.TransferredFrom = &from
+	i.UpdatedAt = now
+
+	return &IssueTransferred{
+		IssueID:       i.ID,
+		From:          from,
+		To:            to,
+		Reason:        reason,
+		TransferredAt: Timestamp(now),
+	}
+}
@@ -45,6 +45,7 @@ func TestIssue_IsReady(t *testing.T) {
 func TestIssue_CanBeClaimed(t *testing.T) {
 	agent := AgentName("test-agent")
 	highPriority := PriorityHigh
+	mediumPriority := PriorityMedium
 
 	tests := []struct {
 		name     string
@@ -70,6 +71,42 @@ func TestIssue_CanBeClaimed(t *testing.T) {
 			filters:  ClaimFilters{OnlyUnassigned: true},
 			expected: true,
 		},
+		{
+			name:     "include types - matching type",
+			issue:    Issue{Status: StatusOpen, IssueType: "bug"},
+			filters:  ClaimFilters{IncludeTypes: []string{"bug", "feature"}},
+			expected: true,
+		},
+		{
+			name:     "include types - other type",
+			issue:    Issue{Status: StatusOpen, IssueType: "chore"},
+			filters:  ClaimFilters{IncludeTypes: []string{"bug", "feature"}},
+			expected: false,
+		},
+		{
+			name:     "exclude types - excluded type",
+			issue:    Issue{Status: StatusOpen, IssueType: "epic"},
+			filters:  ClaimFilters{ExcludeTypes: []string{"epic"}},
+			expected: false,
+		},
+		{
+			name:     "exclude types - issue without a type",
+			issue:    Issue{Status: StatusOpen},
+			filters:  ClaimFilters{ExcludeTypes: []string{"epic"}},
+			expected: true,
+		},
+		{
+			name:     "where expression - matching labels",
+			issue:    Issue{Status: StatusOpen, Labels: LabelSet{"docs"}},
+			filters:  ClaimFilters{Where: OrExpr{X: LabelTerm{Label: "frontend"}, Y: LabelTerm{Label: "docs"}}},
+			expected: true,
+		},
+		{
+			name:     "where expression - no matching labels",
+			issue:    Issue{Status: StatusOpen, Labels: LabelSet{"backend"}},
+			filters:  ClaimFilters{Where: OrExpr{X: LabelTerm{Label: "frontend"}, Y: LabelTerm{Label: "docs"}}},
+			expected: false,
+		},
 		{
 			name:     "only unassigned - assigned issue",
 			issue:    Issue{Status: StatusOpen, Blocked: false, Assignee: &agent},
@@ -101,17 +138,35 @@ func TestIssue_CanBeClaimed(t *testing.T) {
 			expected: true,
 		},
 		{
-			name:     "min priority - meets",
+			name:     "max priority - more urgent",
+			issue:    Issue{Status: StatusOpen, Blocked: false, Priority: PriorityCritical},
+			filters:  ClaimFilters{MaxPriority: &highPriority},
+			expected: true,
+		},
+		{
+			name:     "max priority - meets",
 			issue:    Issue{Status: StatusOpen, Blocked: false, Priority: PriorityHigh},
-			filters:  ClaimFilters{MinPriority: &highPriority},
+			filters:  ClaimFilters{MaxPriority: &highPriority},
 			expected: true,
 		},
 		{
-			name:     "min priority - below",
+			name:     "max priority - less urgent",
 			issue:    Issue{Status: StatusOpen, Blocked: false, Priority: PriorityLow},
-			filters:  ClaimFilters{MinPriority: &highPriority},
+			filters:  ClaimFilters{MaxPriority: &highPriority},
+			expected: false,
+		},
+		{
+			name:     "min priority - more urgent than range",
+			issue:    Issue{Status: StatusOpen, Blocked: false, Priority: PriorityCritical},
+			filters:  ClaimFilters{MinPriority: &highPriority, MaxPriority: &mediumPriority},
 			expected: false,
 		},
+		{
+			name:     "priority range - within",
+			issue:    Issue{Status: StatusOpen, Blocked: false, Priority: PriorityMedium},
+			filters:  ClaimFilters{MinPriority: &highPriority, MaxPriority: &mediumPriority},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -159,3 +214,127 @@ func TestIssue_Claim(t *testing.T) {
 		t.Errorf("expected event Agent to be %s, got %s", agent, event.Agent)
 	}
 }
+
+func TestIssue_IsOwnedBy(t *testing.T) {
+	agent := AgentName("test-agent")
+	other := AgentName("other-agent")
+
+	tests := []struct {
+		name     string
+		issue    Issue
+		expected bool
+	}{
+		{"in progress and assigned", Issue{Status: StatusInProgress, Assignee: &agent}, true},
+		{"assigned to another agent", Issue{Status: StatusInProgress, Assignee: &other}, false},
+		{"unassigned", Issue{Status: StatusInProgress}, false},
+		{"assigned but open", Issue{Status: StatusOpen, Assignee: &agent}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.issue.IsOwnedBy(agent) != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, tt.issue.IsOwnedBy(agent))
+			}
+		})
+	}
+}
+
+func TestIssue_Release(t *testing.T) {
+	agent := AgentName("test-agent")
+	issue := Issue{
+		ID:       IssueId("test-123"),
+		Status:   StatusInProgress,
+		Assignee: &agent,
+	}
+	now := time.Now()
+
+	event := issue.Release(agent, "blocked on credentials", now)
+
+	if issue.Status != StatusOpen {
+		t.Errorf("expected status to be open, got %s", issue.Status)
+	}
+	if issue.Assignee != nil {
+		t.Error("expected assignee to be cleared")
+	}
+	if !issue.UpdatedAt.Equal(now) {
+		t.Error("expected UpdatedAt to be updated")
+	}
+	if event.IssueID != issue.ID || event.Agent != agent || event.Reason != "blocked on credentials" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestIssue_Close(t *testing.T) {
+	agent := AgentName("test-agent")
+	issue := Issue{
+		ID:       IssueId("test-123"),
+		Status:   StatusInProgress,
+		Assignee: &agent,
+	}
+	now := time.Now()
+
+	event := issue.Close(agent, "shipped", now)
+
+	if issue.Status != StatusClosed {
+		t.Errorf("expected status to be closed, got %s", issue.Status)
+	}
+	if issue.Assignee == nil || *issue.Assignee != agent {
+		t.Error("expected assignee to be kept")
+	}
+	if issue.ClosedAt == nil || !issue.ClosedAt.Equal(now) || !issue.UpdatedAt.Equal(now) {
+		t.Error("expected ClosedAt and UpdatedAt to be set")
+	}
+	if event.IssueID != issue.ID || event.Agent != agent || event.Reason != "shipped" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestIssue_Transfer(t *testing.T) {
+	from := AgentName("planner")
+	issue := Issue{
+		ID:       IssueId("test-123"),
+		Status:   StatusInProgress,
+		Assignee: &from,
+	}
+	now := time.Now()
+
+	event := issue.Transfer(from, "specialist", "needs a DB expert", now)
+
+	if issue.Status != StatusInProgress {
+		t.Errorf("expected status to stay in_progress, got %s", issue.Status)
+	}
+	if issue.Assignee == nil || *issue.Assignee != "specialist" {
+		t.Errorf("expected assignee specialist, got %v", issue.Assignee)
+	}
+	if issue.TransferredFrom == nil || *issue.TransferredFrom != from || !issue.UpdatedAt.Equal(now) {
+		t.Error("expected TransferredFrom and UpdatedAt to be set")
+	}
+	if event.From != from || event.To != "specialist" || event.Reason != "needs a DB expert" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestIssue_LeaseExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		issue    Issue
+		expected bool
+	}{
+		{"no lease", Issue{Status: StatusInProgress}, false},
+		{"live lease", Issue{Status: StatusInProgress, LeaseExpiresAt: &future}, false},
+		{"expired lease", Issue{Status: StatusInProgress, LeaseExpiresAt: &past}, true},
+		{"expired lease but open", Issue{Status: StatusOpen, LeaseExpiresAt: &past}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.issue.LeaseExpired(now) != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, tt.issue.LeaseExpired(now))
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -64,15 +66,103 @@ func (s IssueStatus) IsClaimable() bool {
 	return s == StatusOpen
 }
 
-// Priority represents the priority level of an issue.
+// Priority represents the priority level of an issue on the Beads 0-4 scale,
+// where P0 is the most urgent and P4 is backlog.
 type Priority int
 
 const (
-	PriorityLow    Priority = 0
-	PriorityMedium Priority = 1
-	PriorityHigh   Priority = 2
+	PriorityCritical Priority = 0
+	PriorityHigh     Priority = 1
+	PriorityMedium   Priority = 2
+	PriorityLow      Priority = 3
+	PriorityBacklog  Priority = 4
 )
 
+var priorityNames = map[Priority]string{
+	PriorityCritical: "critical",
+	PriorityHigh:     "high",
+	PriorityMedium:   "medium",
+	PriorityLow:      "low",
+	PriorityBacklog:  "backlog",
+}
+
+// NewPriority creates a validated Priority from its Beads number.
+func NewPriority(p int) (Priority, error) {
+	if p < int(PriorityCritical) || p > int(PriorityBacklog) {
+		return 0, fmt.Errorf("priority %d out of range; must be between 0 and 4", p)
+	}
+	return Priority(p), nil
+}
+
+// ParsePriority parses a priority written as "2" or "P2".
+func ParsePriority(s string) (Priority, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(s), "P"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid priority %q; expected 0-4 or P0-P4", s)
+	}
+	return NewPriority(n)
+}
+
+// ParsePriorityRange parses a single priority ("P1") or an inclusive range
+// ("P0-P2", "1-3") into its most and least urgent bounds.
+func ParsePriorityRange(s string) (Priority, Priority, error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		p, err := ParsePriority(s)
+		return p, p, err
+	}
+
+	minP, err := ParsePriority(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxP, err := ParsePriority(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	if minP > maxP {
+		return 0, 0, fmt.Errorf("invalid priority range %q; lower bound must not exceed upper bound", s)
+	}
+	return minP, maxP, nil
+}
+
+// ParseLabelLimit parses a label concurrency limit written as "label=N".
+func ParseLabelLimit(s string) (string, int, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid label limit %q; expected label=N", s)
+	}
+
+	label := strings.TrimSpace(s[:i])
+	if label == "" {
+		return "", 0, fmt.Errorf("invalid label limit %q; label cannot be empty", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s[i+1:]))
+	if err != nil || n < 0 {
+		return "", 0, fmt.Errorf("invalid label limit %q; N must be a non-negative integer", s)
+	}
+	return label, n, nil
+}
+
+// String returns the Beads short name of the priority, e.g. "P0".
+func (p Priority) String() string {
+	return "P" + strconv.Itoa(int(p))
+}
+
+// Name returns the descriptive name of the priority, e.g. "critical".
+func (p Priority) Name() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// IsValid returns true if the priority is within the Beads 0-4 scale.
+func (p Priority) IsValid() bool {
+	return p >= PriorityCritical && p <= PriorityBacklog
+}
+
 // LabelSet represents a collection of labels on an issue.
 type LabelSet []string
 
@@ -106,12 +196,125 @@ func (ls LabelSet) ContainsAll(labels []string) bool {
 	return true
 }
 
+// WithPrefix returns the labels that start with any of the prefixes.
+func (ls LabelSet) WithPrefix(prefixes []string) LabelSet {
+	var matched LabelSet
+	for _, l := range ls {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(l, prefix) {
+				matched = append(matched, l)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// ReadinessStrategy selects how an issue is judged unblocked.
+type ReadinessStrategy string
+
+const (
+	// ReadinessAuto trusts blocked_issues_cache when the table exists and
+	// falls back to the dependency graph otherwise.
+	ReadinessAuto ReadinessStrategy = "auto"
+	// ReadinessCache trusts the blocked_issues_cache Beads maintains.
+	ReadinessCache ReadinessStrategy = "cache"
+	// ReadinessDependencies recomputes blocking from the dependencies table,
+	// like the Beads ready_issues view.
+	ReadinessDependencies ReadinessStrategy = "dependencies"
+)
+
+// ParseReadinessStrategy parses a strategy name. An empty name means auto.
+func ParseReadinessStrategy(s string) (ReadinessStrategy, error) {
+	switch ReadinessStrategy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ReadinessAuto:
+		return ReadinessAuto, nil
+	case ReadinessCache:
+		return ReadinessCache, nil
+	case ReadinessDependencies:
+		return ReadinessDependencies, nil
+	}
+	return "", fmt.Errorf("invalid readiness strategy %q: must be auto, cache or dependencies", s)
+}
+
+// SelectionStrategy chooses the order in which ready issues are claimed.
+type SelectionStrategy string
+
+const (
+	// StrategyPriority claims the most urgent issue first, then the oldest.
+	StrategyPriority SelectionStrategy = "priority"
+	// StrategyCriticalPath claims first the issue that unblocks the most
+	// other issues, directly or transitively, falling back to priority.
+	StrategyCriticalPath SelectionStrategy = "critical-path"
+)
+
+// ParseSelectionStrategy parses a strategy name. An empty name means priority.
+func ParseSelectionStrategy(s string) (SelectionStrategy, error) {
+	switch SelectionStrategy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", StrategyPriority:
+		return StrategyPriority, nil
+	case StrategyCriticalPath:
+		return StrategyCriticalPath, nil
+	}
+	return "", fmt.Errorf("invalid strategy %q: must be priority or critical-path", s)
+}
+
+// DefaultTypeOrder ranks Beads issue types among issues of equal priority:
+// broken things are fixed before new work starts, and chores come last.
+// Types it does not list rank after all of these.
+var DefaultTypeOrder = []string{"bug", "feature", "task", "epic", "chore"}
+
 // ClaimFilters represents the filtering options for claiming issues.
+//
+// Where, if set, is a label expression the issue must also satisfy. It is how
+// an agent asks for any of several labels, which IncludeLabels cannot express.
+//
+// IncludeTypes admits only issues of those issue types; ExcludeTypes skips
+// issues of those types.
+//
+// Parent, if set, restricts candidates to descendants of that issue through
+// parent-child dependencies, or to its immediate children if DirectChildren
+// is set. An issue does not know its ancestors, so only the repository
+// enforces it.
+//
+// MinPriority and MaxPriority bound the Beads priority number inclusively.
+// Lower numbers are more urgent, so MaxPriority=P1 admits only P0 and P1.
+//
+// LabelLimits caps, per label, how many issues carrying it may be in progress
+// across all agents; an issue is skipped while any of its labels is at its
+// limit. The limit depends on other issues, so only the repository enforces it.
+//
+// ExclusivePrefixes name label prefixes such as "component:" whose labels mark
+// mutually exclusive work: an issue labelled "component:api" is skipped while
+// another issue labelled "component:api" is in progress. Like LabelLimits,
+// only the repository enforces them.
+//
+// An empty Readiness means ReadinessAuto, and an empty Strategy means
+// StrategyPriority.
+//
+// PreferLabels and TypeOrder do not filter. Among issues of equal priority,
+// those carrying more preferred labels are claimed first, then types rank by
+// TypeOrder, which defaults to DefaultTypeOrder. Profile names the agent
+// profile the filters were derived from, if any, and is reported back to the
+// agent.
 type ClaimFilters struct {
-	OnlyUnassigned bool
-	IncludeLabels  []string
-	ExcludeLabels  []string
-	MinPriority    *Priority
+	OnlyUnassigned    bool
+	IncludeLabels     []string
+	ExcludeLabels     []string
+	Where             LabelExpr
+	IncludeTypes      []string
+	ExcludeTypes      []string
+	Parent            IssueId
+	DirectChildren    bool
+	MinPriority       *Priority
+	MaxPriority       *Priority
+	LabelLimits       map[string]int
+	ExclusivePrefixes []string
+	Readiness         ReadinessStrategy
+	Strategy          SelectionStrategy
+	PreferLabels      []string
+	TypeOrder         []string
+	Profile           string
 }
 
 // NewClaimFilters creates a new ClaimFilters with default values.
@@ -121,7 +324,31 @@ func NewClaimFilters() ClaimFilters {
 		IncludeLabels:  nil,
 		ExcludeLabels:  nil,
 		MinPriority:    nil,
+		MaxPriority:    nil,
+	}
+}
+
+// ClaimOptions controls how a claim is held, as opposed to which issues are
+// eligible for claiming.
+type ClaimOptions struct {
+	// Lease is how long the claim lasts before other agents may reclaim the
+	// issue. Zero means the claim never expires.
+	Lease time.Duration
+
+	// MaxInProgress caps how many issues the agent may hold in progress,
+	// counting the ones being claimed. Zero means no limit.
+	MaxInProgress int
+}
+
+// Validate checks that the options are usable.
+func (o ClaimOptions) Validate() error {
+	if o.Lease < 0 {
+		return errors.New("lease duration cannot be negative")
+	}
+	if o.MaxInProgress < 0 {
+		return errors.New("max in progress cannot be negative")
 	}
+	return nil
 }
 
 // Timestamp represents a point in time.
@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestIssueId_String(t *testing.T) {
@@ -127,6 +129,19 @@ func TestLabelSet_ContainsAll(t *testing.T) {
 	}
 }
 
+func TestLabelSet_WithPrefix(t *testing.T) {
+	ls := LabelSet{"backend", "component:api", "area:db", "component:cli"}
+
+	got := ls.WithPrefix([]string{"component:", "area:"})
+	want := LabelSet{"component:api", "area:db", "component:cli"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := ls.WithPrefix(nil); got != nil {
+		t.Errorf("expected no labels without prefixes, got %v", got)
+	}
+}
+
 func TestNewClaimFilters(t *testing.T) {
 	filters := NewClaimFilters()
 	if filters.OnlyUnassigned {
@@ -141,6 +156,9 @@ func TestNewClaimFilters(t *testing.T) {
 	if filters.MinPriority != nil {
 		t.Error("expected MinPriority to be nil")
 	}
+	if filters.MaxPriority != nil {
+		t.Error("expected MaxPriority to be nil")
+	}
 }
 
 func TestTimestamp(t *testing.T) {
@@ -157,13 +175,195 @@ func TestTimestamp(t *testing.T) {
 }
 
 func TestPriorityConstants(t *testing.T) {
-	if PriorityLow != 0 {
-		t.Errorf("expected PriorityLow to be 0, got %d", PriorityLow)
+	expected := map[Priority]int{
+		PriorityCritical: 0,
+		PriorityHigh:     1,
+		PriorityMedium:   2,
+		PriorityLow:      3,
+		PriorityBacklog:  4,
+	}
+	for p, n := range expected {
+		if int(p) != n {
+			t.Errorf("expected %s to be %d, got %d", p.Name(), n, p)
+		}
+	}
+}
+
+func TestNewPriority(t *testing.T) {
+	for n := 0; n <= 4; n++ {
+		if _, err := NewPriority(n); err != nil {
+			t.Errorf("unexpected error for %d: %v", n, err)
+		}
+	}
+	for _, n := range []int{-1, 5} {
+		if _, err := NewPriority(n); err == nil {
+			t.Errorf("expected error for %d", n)
+		}
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Priority
+		wantErr  bool
+	}{
+		{"0", PriorityCritical, false},
+		{"P1", PriorityHigh, false},
+		{"p2", PriorityMedium, false},
+		{" 4 ", PriorityBacklog, false},
+		{"P5", 0, true},
+		{"high", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := ParsePriority(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePriority(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && p != tt.expected {
+				t.Errorf("ParsePriority(%q) = %d, expected %d", tt.input, p, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePriorityRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		min     Priority
+		max     Priority
+		wantErr bool
+	}{
+		{"P1", PriorityHigh, PriorityHigh, false},
+		{"0-2", PriorityCritical, PriorityMedium, false},
+		{"P1-P3", PriorityHigh, PriorityLow, false},
+		{"3-1", 0, 0, true},
+		{"P0-x", 0, 0, true},
+		{"x-P2", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			minP, maxP, err := ParsePriorityRange(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePriorityRange(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && (minP != tt.min || maxP != tt.max) {
+				t.Errorf("ParsePriorityRange(%q) = %d-%d, expected %d-%d", tt.input, minP, maxP, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestParseLabelLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		label   string
+		limit   int
+		wantErr bool
+	}{
+		{"db-migration=2", "db-migration", 2, false},
+		{" release = 1 ", "release", 1, false},
+		{"area=db=0", "area=db", 0, false},
+		{"release", "", 0, true},
+		{"=2", "", 0, true},
+		{"release=-1", "", 0, true},
+		{"release=x", "", 0, true},
 	}
-	if PriorityMedium != 1 {
-		t.Errorf("expected PriorityMedium to be 1, got %d", PriorityMedium)
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			label, limit, err := ParseLabelLimit(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabelLimit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && (label != tt.label || limit != tt.limit) {
+				t.Errorf("ParseLabelLimit(%q) = %q, %d, expected %q, %d", tt.input, label, limit, tt.label, tt.limit)
+			}
+		})
+	}
+}
+
+func TestPriority_StringAndName(t *testing.T) {
+	if PriorityCritical.String() != "P0" {
+		t.Errorf("expected P0, got %s", PriorityCritical.String())
+	}
+	if PriorityBacklog.Name() != "backlog" {
+		t.Errorf("expected backlog, got %s", PriorityBacklog.Name())
+	}
+	if Priority(9).Name() != "unknown" {
+		t.Errorf("expected unknown, got %s", Priority(9).Name())
+	}
+	if !PriorityLow.IsValid() || Priority(5).IsValid() || Priority(-1).IsValid() {
+		t.Error("unexpected IsValid result")
+	}
+}
+
+func TestClaimOptions_Validate(t *testing.T) {
+	if err := (ClaimOptions{}).Validate(); err != nil {
+		t.Errorf("expected zero options to be valid, got %v", err)
+	}
+	if err := (ClaimOptions{Lease: time.Minute}).Validate(); err != nil {
+		t.Errorf("expected positive lease to be valid, got %v", err)
+	}
+	if err := (ClaimOptions{Lease: -time.Minute}).Validate(); err == nil {
+		t.Error("expected error for negative lease")
+	}
+	if err := (ClaimOptions{MaxInProgress: -1}).Validate(); err == nil {
+		t.Error("expected error for negative max in progress")
+	}
+}
+
+func TestParseReadinessStrategy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ReadinessStrategy
+		wantErr  bool
+	}{
+		{"", ReadinessAuto, false},
+		{"auto", ReadinessAuto, false},
+		{"cache", ReadinessCache, false},
+		{"Dependencies", ReadinessDependencies, false},
+		{"graph", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseReadinessStrategy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReadinessStrategy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseReadinessStrategy(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
 	}
-	if PriorityHigh != 2 {
-		t.Errorf("expected PriorityHigh to be 2, got %d", PriorityHigh)
+}
+
+func TestParseSelectionStrategy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SelectionStrategy
+		wantErr  bool
+	}{
+		{"", StrategyPriority, false},
+		{"priority", StrategyPriority, false},
+		{"Critical-Path", StrategyCriticalPath, false},
+		{"random", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSelectionStrategy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelectionStrategy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseSelectionStrategy(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
 	}
 }
@@ -0,0 +1,324 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// Beads event types written to the events audit table.
+const (
+	eventStatusChanged   = "status_changed"
+	eventAssigneeChanged = "assignee_changed"
+	eventClosed          = "closed"
+)
+
+// beadsEvent is a single row destined for the Beads events table.
+type beadsEvent struct {
+	issueID   domain.IssueId
+	eventType string
+	actor     domain.AgentName
+	oldValue  sql.NullString
+	newValue  sql.NullString
+	comment   sql.NullString
+}
+
+// nullString converts a string to a valid sql.NullString.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+// claimEvents builds the audit events for an issue moving from open to in_progress.
+func claimEvents(issueID domain.IssueId, agent domain.AgentName, oldAssignee sql.NullString) []beadsEvent {
+	events := []beadsEvent{{
+		issueID:   issueID,
+		eventType: eventStatusChanged,
+		actor:     agent,
+		oldValue:  nullString(string(domain.StatusOpen)),
+		newValue:  nullString(string(domain.StatusInProgress)),
+	}}
+
+	if !oldAssignee.Valid || oldAssignee.String != agent.String() {
+		events = append(events, beadsEvent{
+			issueID:   issueID,
+			eventType: eventAssigneeChanged,
+			actor:     agent,
+			oldValue:  oldAssignee,
+			newValue:  nullString(agent.String()),
+		})
+	}
+
+	return events
+}
+
+// releaseEvents builds the audit events for an issue handed back to the ready pool.
+func releaseEvents(issueID domain.IssueId, agent domain.AgentName, reason string) []beadsEvent {
+	status := beadsEvent{
+		issueID:   issueID,
+		eventType: eventStatusChanged,
+		actor:     agent,
+		oldValue:  nullString(string(domain.StatusInProgress)),
+		newValue:  nullString(string(domain.StatusOpen)),
+	}
+	if reason != "" {
+		status.comment = nullString(reason)
+	}
+
+	return []beadsEvent{status, {
+		issueID:   issueID,
+		eventType: eventAssigneeChanged,
+		actor:     agent,
+		oldValue:  nullString(agent.String()),
+	}}
+}
+
+// closeEvents builds the audit event for an issue closed by its owner. Beads
+// records closes as a single 'closed' event carrying the reason.
+func closeEvents(issueID domain.IssueId, agent domain.AgentName, reason string) []beadsEvent {
+	closed := beadsEvent{
+		issueID:   issueID,
+		eventType: eventClosed,
+		actor:     agent,
+		oldValue:  nullString(string(domain.StatusInProgress)),
+		newValue:  nullString(string(domain.StatusClosed)),
+	}
+	if reason != "" {
+		closed.comment = nullString(reason)
+	}
+	return []beadsEvent{closed}
+}
+
+// transferEvents builds the audit event for an issue handed from one agent to
+// another. The status stays in_progress; the comment names both agents.
+func transferEvents(issueID domain.IssueId, from, to domain.AgentName, reason string) []beadsEvent {
+	comment := "handed off from " + from.String() + " to " + to.String()
+	if reason != "" {
+		comment += ": " + reason
+	}
+
+	return []beadsEvent{{
+		issueID:   issueID,
+		eventType: eventAssigneeChanged,
+		actor:     from,
+		oldValue:  nullString(from.String()),
+		newValue:  nullString(to.String()),
+		comment:   nullString(comment),
+	}}
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// hasTable reports whether the database contains the named table.
+func hasTable(ctx context.Context, q queryRower, name string) (bool, error) {
+	var count int
+	err := q.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name,
+	).Scan(&count)
+	if err != nil {
+		return false, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to inspect schema: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return count > 0, nil
+}
+
+// hasColumn reports whether the named table contains the named column.
+func hasColumn(ctx context.Context, tx *sql.Tx, table, column string) (bool, error) {
+	var count int
+	err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column,
+	).Scan(&count)
+	if err != nil {
+		return false, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to inspect schema: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return count > 0, nil
+}
+
+// recordEvents appends rows to the Beads events table inside tx.
+// Databases without an events table (very old or minimal schemas) are left untouched.
+func recordEvents(ctx context.Context, tx *sql.Tx, events []beadsEvent, now time.Time) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ok, err := hasTable(ctx, tx, "events")
+	if err != nil || !ok {
+		return err
+	}
+
+	for _, e := range events {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO events (issue_id, event_type, actor, old_value, new_value, comment, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, e.issueID.String(), e.eventType, e.actor.String(), e.oldValue, e.newValue, e.comment,
+			now.Format(time.RFC3339Nano))
+		if err != nil {
+			return &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to record event: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+	}
+
+	return nil
+}
+
+// addComment appends a comment to the issue, visible through `bd comments`.
+// Databases without a comments table are left untouched.
+func addComment(ctx context.Context, tx *sql.Tx, issueID domain.IssueId, author domain.AgentName, text string, now time.Time) error {
+	ok, err := hasTable(ctx, tx, "comments")
+	if err != nil || !ok {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO comments (issue_id, author, text, created_at)
+		VALUES (?, ?, ?, ?)
+	`, issueID.String(), author.String(), text, now.Format(time.RFC3339Nano))
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to add comment: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return nil
+}
+
+// beadsContent holds the issue fields Beads hashes into issues.content_hash.
+type beadsContent struct {
+	title              string
+	description        string
+	design             string
+	acceptanceCriteria string
+	notes              string
+	status             string
+	priority           int
+	issueType          string
+	assignee           string
+	externalRef        sql.NullString
+}
+
+// contentHash mirrors Beads' Issue.ComputeContentHash so that the hash of an
+// issue modified by bd-claim matches what bd itself would compute.
+func (c beadsContent) contentHash() string {
+	h := sha256.New()
+
+	for _, field := range []string{
+		c.title,
+		c.description,
+		c.design,
+		c.acceptanceCriteria,
+		c.notes,
+		c.status,
+		fmt.Sprintf("%d", c.priority),
+		c.issueType,
+		c.assignee,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+
+	if c.externalRef.Valid {
+		h.Write([]byte(c.externalRef.String))
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// markDirty refreshes the issue's content hash and flags it in dirty_issues so
+// the Beads auto-flush exports the change to JSONL, exactly as `bd update` does.
+// Either step is skipped when the database predates the corresponding schema.
+func markDirty(ctx context.Context, tx *sql.Tx, issueID domain.IssueId, now time.Time) error {
+	ok, err := hasColumn(ctx, tx, "issues", "content_hash")
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := refreshContentHash(ctx, tx, issueID); err != nil {
+			return err
+		}
+	}
+
+	ok, err = hasTable(ctx, tx, "dirty_issues")
+	if err != nil || !ok {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO dirty_issues (issue_id, marked_at)
+		VALUES (?, ?)
+		ON CONFLICT (issue_id) DO UPDATE SET marked_at = excluded.marked_at
+	`, issueID.String(), now.Format(time.RFC3339Nano))
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to mark issue dirty: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return nil
+}
+
+func refreshContentHash(ctx context.Context, tx *sql.Tx, issueID domain.IssueId) error {
+	var c beadsContent
+	var description, design, acceptanceCriteria, notes, issueType, assignee sql.NullString
+	err := tx.QueryRowContext(ctx, `
+		SELECT title, description, design, acceptance_criteria, notes,
+			   status, priority, issue_type, assignee, external_ref
+		FROM issues
+		WHERE id = ?
+	`, issueID.String()).Scan(
+		&c.title,
+		&description,
+		&design,
+		&acceptanceCriteria,
+		&notes,
+		&c.status,
+		&c.priority,
+		&issueType,
+		&assignee,
+		&c.externalRef,
+	)
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to read issue content: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	c.description = description.String
+	c.design = design.String
+	c.acceptanceCriteria = acceptanceCriteria.String
+	c.notes = notes.String
+	c.issueType = issueType.String
+	c.assignee = assignee.String
+
+	_, err = tx.ExecContext(ctx, `UPDATE issues SET content_hash = ? WHERE id = ?`,
+		c.contentHash(), issueID.String())
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to update content hash: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// addBeadsAuditTables adds the Beads audit tables that setupTestDB omits.
+func addBeadsAuditTables(t *testing.T, dbPath string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			issue_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			actor TEXT NOT NULL,
+			old_value TEXT,
+			new_value TEXT,
+			comment TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE dirty_issues (
+			issue_id TEXT PRIMARY KEY,
+			marked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			issue_id TEXT NOT NULL,
+			author TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupBeadsSchemaDB creates a database with the Beads issues schema, including
+// content_hash and the text columns that feed it.
+func setupBeadsSchemaDB(t *testing.T) (string, func()) {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "sqlite-beads-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbPath := filepath.Join(tmpDir, "beads.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		t.Fatal(err)
+	}
+
+	schema := `
+		CREATE TABLE issues (
+			id TEXT PRIMARY KEY,
+			content_hash TEXT,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			design TEXT NOT NULL DEFAULT '',
+			acceptance_criteria TEXT NOT NULL DEFAULT '',
+			notes TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'open',
+			priority INTEGER NOT NULL DEFAULT 2,
+			issue_type TEXT NOT NULL DEFAULT 'task',
+			assignee TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			closed_at DATETIME,
+			external_ref TEXT,
+			CHECK ((status = 'closed') = (closed_at IS NOT NULL))
+		);
+		CREATE TABLE labels (
+			issue_id TEXT NOT NULL,
+			label TEXT NOT NULL,
+			PRIMARY KEY (issue_id, label)
+		);
+		CREATE TABLE blocked_issues_cache (
+			issue_id TEXT PRIMARY KEY
+		);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		os.RemoveAll(tmpDir)
+		t.Fatal(err)
+	}
+	db.Close()
+
+	addBeadsAuditTables(t, dbPath)
+
+	return dbPath, func() { os.RemoveAll(tmpDir) }
+}
+
+func fetchTestComments(t *testing.T, dbPath, issueID string) []string {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT text FROM comments WHERE issue_id = ? ORDER BY id`, issueID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var comments []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			t.Fatal(err)
+		}
+		comments = append(comments, text)
+	}
+	return comments
+}
+
+type testEvent struct {
+	eventType string
+	actor     string
+	oldValue  sql.NullString
+	newValue  sql.NullString
+	comment   sql.NullString
+}
+
+func fetchTestEvents(t *testing.T, dbPath, issueID string) []testEvent {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT event_type, actor, old_value, new_value, comment
+		FROM events WHERE issue_id = ? ORDER BY id
+	`, issueID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var events []testEvent
+	for rows.Next() {
+		var e testEvent
+		if err := rows.Scan(&e.eventType, &e.actor, &e.oldValue, &e.newValue, &e.comment); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestClaimEvents(t *testing.T) {
+	agent := domain.AgentName("agent-1")
+
+	events := claimEvents("issue-1", agent, sql.NullString{})
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].eventType != eventStatusChanged {
+		t.Errorf("expected first event %s, got %s", eventStatusChanged, events[0].eventType)
+	}
+	if events[1].eventType != eventAssigneeChanged || events[1].oldValue.Valid {
+		t.Errorf("expected assignee change from NULL, got %+v", events[1])
+	}
+
+	// Re-claiming an issue already assigned to the agent only changes status
+	events = claimEvents("issue-1", agent, nullString("agent-1"))
+	if len(events) != 1 {
+		t.Errorf("expected 1 event, got %d", len(events))
+	}
+}
+
+func TestTransferEvents(t *testing.T) {
+	events := transferEvents("issue-1", "planner", "specialist", "needs a DB expert")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.eventType != eventAssigneeChanged || e.actor != "planner" || e.oldValue.String != "planner" || e.newValue.String != "specialist" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e.comment.String != "handed off from planner to specialist: needs a DB expert" {
+		t.Errorf("unexpected comment: %q", e.comment.String)
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimRecordsEvents(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	addBeadsAuditTables(t, dbPath)
+
+	previous := "old-agent"
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, &previous)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	if _, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events := fetchTestEvents(t, dbPath, "issue-1")
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	status := events[0]
+	if status.eventType != "status_changed" || status.actor != "test-agent" ||
+		status.oldValue.String != "open" || status.newValue.String != "in_progress" {
+		t.Errorf("unexpected status event: %+v", status)
+	}
+
+	assignee := events[1]
+	if assignee.eventType != "assignee_changed" || assignee.actor != "test-agent" ||
+		assignee.oldValue.String != "old-agent" || assignee.newValue.String != "test-agent" {
+		t.Errorf("unexpected assignee event: %+v", assignee)
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimWithoutEventsTable(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil {
+		t.Fatal("expected issue to be claimed")
+	}
+}
+
+func TestBeadsContent_ContentHash(t *testing.T) {
+	base := beadsContent{title: "Title", status: "open", priority: 2, issueType: "task"}
+
+	if base.contentHash() != base.contentHash() {
+		t.Error("expected hash to be deterministic")
+	}
+	if len(base.contentHash()) != 64 {
+		t.Errorf("expected sha256 hex digest, got %q", base.contentHash())
+	}
+
+	claimed := base
+	claimed.status = "in_progress"
+	claimed.assignee = "agent-1"
+	if claimed.contentHash() == base.contentHash() {
+		t.Error("expected status and assignee to change the hash")
+	}
+
+	withRef := base
+	withRef.externalRef = nullString("gh-9")
+	if withRef.contentHash() == base.contentHash() {
+		t.Error("expected external_ref to change the hash")
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimMarksDirty(t *testing.T) {
+	dbPath, cleanup := setupBeadsSchemaDB(t)
+	defer cleanup()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		INSERT INTO issues (id, content_hash, title, description, status, priority, created_at, updated_at)
+		VALUES ('bd-1', 'stale', 'Fix it', 'details', 'open', 1, ?, ?)
+	`, time.Now().Format(time.RFC3339Nano), time.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil {
+		t.Fatal("expected issue to be claimed")
+	}
+
+	var dirty int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM dirty_issues WHERE issue_id = 'bd-1'`).Scan(&dirty); err != nil {
+		t.Fatal(err)
+	}
+	if dirty != 1 {
+		t.Errorf("expected issue to be marked dirty, got %d rows", dirty)
+	}
+
+	var hash string
+	if err := db.QueryRow(`SELECT content_hash FROM issues WHERE id = 'bd-1'`).Scan(&hash); err != nil {
+		t.Fatal(err)
+	}
+	expected := beadsContent{
+		title:       "Fix it",
+		description: "details",
+		status:      "in_progress",
+		priority:    1,
+		issueType:   "task",
+		assignee:    "test-agent",
+	}.contentHash()
+	if hash != expected {
+		t.Errorf("expected content_hash %s, got %s", expected, hash)
+	}
+}
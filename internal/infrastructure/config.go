@@ -0,0 +1,206 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// ClaimConfigFile is bd-claim's optional settings file. It lives next to the
+// Beads database, normally in the workspace's .beads directory.
+const ClaimConfigFile = "bd-claim.json"
+
+// ClaimConfig holds workspace-wide claim defaults. Command-line flags and
+// request parameters override them.
+type ClaimConfig struct {
+	// MaxInProgress is the default per-agent limit on issues in progress.
+	// Zero means no limit.
+	MaxInProgress int `json:"max_in_progress"`
+
+	// LabelLimits caps how many issues with each label may be in progress
+	// across all agents, e.g. {"db-migration": 2}.
+	LabelLimits map[string]int `json:"label_limits"`
+
+	// ExclusivePrefixes are label prefixes, e.g. "component:", whose labels
+	// may each be carried by only one issue in progress at a time.
+	ExclusivePrefixes []string `json:"exclusive_prefixes"`
+
+	// TypeOrder ranks issue types among issues of equal priority, replacing
+	// domain.DefaultTypeOrder.
+	TypeOrder []string `json:"type_order"`
+
+	// Profiles describe what each agent is good at, keyed by agent name or
+	// by a glob such as "fe-*".
+	Profiles ClaimProfiles `json:"profiles"`
+}
+
+// ClaimProfiles maps agent names and globs to profiles.
+type ClaimProfiles map[string]ClaimProfile
+
+// ClaimProfile is an agent's capabilities and preferences. Its filters are
+// added to the ones the agent asks for; explicit priority bounds win.
+type ClaimProfile struct {
+	Labels         []string `json:"labels"`
+	ExcludeLabels  []string `json:"exclude_labels"`
+	Where          string   `json:"where"`
+	Types          []string `json:"types"`
+	ExcludeTypes   []string `json:"exclude_types"`
+	Priority       string   `json:"priority"`
+	MaxPriority    string   `json:"max_priority"`
+	OnlyUnassigned bool     `json:"only_unassigned"`
+
+	// PreferLabels rank matching issues first within a priority.
+	PreferLabels []string `json:"prefer_labels"`
+}
+
+// Match returns the profile for agent: the one named after it, or else the
+// first glob in sorted order that matches it.
+func (p ClaimProfiles) Match(agent string) (string, ClaimProfile, bool) {
+	if profile, ok := p[agent]; ok {
+		return agent, profile, true
+	}
+
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if matched, _ := filepath.Match(name, agent); matched {
+			return name, p[name], true
+		}
+	}
+	return "", ClaimProfile{}, false
+}
+
+// LoadClaimConfig reads the config file beside the database at dbPath. A
+// missing file yields the zero config; an unreadable or invalid one fails
+// with INVALID_ARGUMENT so a typo cannot silently disable a limit.
+func LoadClaimConfig(dbPath string) (ClaimConfig, error) {
+	path := filepath.Join(filepath.Dir(dbPath), ClaimConfigFile)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ClaimConfig{}, nil
+	}
+	if err != nil {
+		return ClaimConfig{}, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to read " + path + ": " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	var cfg ClaimConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return ClaimConfig{}, invalidConfig(path, err.Error())
+	}
+	if cfg.MaxInProgress < 0 {
+		return ClaimConfig{}, invalidConfig(path, "max_in_progress cannot be negative")
+	}
+	for label, limit := range cfg.LabelLimits {
+		if limit < 0 {
+			return ClaimConfig{}, invalidConfig(path, fmt.Sprintf("label_limits[%q] cannot be negative", label))
+		}
+	}
+
+	for _, prefix := range cfg.ExclusivePrefixes {
+		if prefix == "" {
+			return ClaimConfig{}, invalidConfig(path, "exclusive_prefixes cannot contain an empty prefix")
+		}
+	}
+
+	for _, issueType := range cfg.TypeOrder {
+		if issueType == "" {
+			return ClaimConfig{}, invalidConfig(path, "type_order cannot contain an empty type")
+		}
+	}
+	for name, profile := range cfg.Profiles {
+		if _, err := filepath.Match(name, ""); err != nil {
+			return ClaimConfig{}, invalidConfig(path, fmt.Sprintf("profile %q is not a valid agent pattern", name))
+		}
+		if profile.Where != "" {
+			if _, err := domain.ParseLabelExpr(profile.Where); err != nil {
+				return ClaimConfig{}, invalidConfig(path, fmt.Sprintf("profile %q: %v", name, err))
+			}
+		}
+		if profile.Priority != "" {
+			if _, _, err := domain.ParsePriorityRange(profile.Priority); err != nil {
+				return ClaimConfig{}, invalidConfig(path, fmt.Sprintf("profile %q: %v", name, err))
+			}
+		}
+		if profile.MaxPriority != "" {
+			if _, err := domain.ParsePriority(profile.MaxPriority); err != nil {
+				return ClaimConfig{}, invalidConfig(path, fmt.Sprintf("profile %q: %v", name, err))
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// ClaimConfigLoader loads a database's claim config for a long-running
+// server. It reloads the file whenever its modification time or size
+// changes, so edits apply to the next request as they would to the next CLI
+// invocation.
+type ClaimConfigLoader struct {
+	dbPath string
+
+	mu     sync.Mutex
+	loaded bool
+	stamp  configStamp
+	config ClaimConfig
+}
+
+// configStamp identifies a version of the config file.
+type configStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// NewClaimConfigLoader returns a loader for the claim config beside dbPath.
+func NewClaimConfigLoader(dbPath string) *ClaimConfigLoader {
+	return &ClaimConfigLoader{dbPath: dbPath}
+}
+
+// Load returns the current claim config. A file that fails to load is not
+// cached, so the error repeats on every call until the file is fixed.
+func (l *ClaimConfigLoader) Load() (ClaimConfig, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Stat before reading: a write in between changes the stamp again
+	var stamp configStamp
+	if info, err := os.Stat(filepath.Join(filepath.Dir(l.dbPath), ClaimConfigFile)); err == nil {
+		stamp = configStamp{exists: true, modTime: info.ModTime(), size: info.Size()}
+	}
+	if l.loaded && stamp == l.stamp {
+		return l.config, nil
+	}
+
+	cfg, err := LoadClaimConfig(l.dbPath)
+	if err != nil {
+		l.loaded = false
+		return ClaimConfig{}, err
+	}
+	l.config, l.stamp, l.loaded = cfg, stamp, true
+	return cfg, nil
+}
+
+func invalidConfig(path, message string) error {
+	return &domain.ClaimFailed{
+		ErrorCode:  domain.ErrCodeInvalidArgument,
+		Message:    "invalid " + path + ": " + message,
+		OccurredAt: domain.Now(),
+	}
+}
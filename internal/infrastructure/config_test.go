@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+func writeClaimConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ClaimConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "beads.db")
+}
+
+func TestLoadClaimConfig_Missing(t *testing.T) {
+	cfg, err := LoadClaimConfig(filepath.Join(t.TempDir(), "beads.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxInProgress != 0 || cfg.LabelLimits != nil {
+		t.Errorf("expected zero config, got %+v", cfg)
+	}
+}
+
+func TestLoadClaimConfig(t *testing.T) {
+	dbPath := writeClaimConfig(t, t.TempDir(), `{"max_in_progress": 2, "label_limits": {"release": 1}, "exclusive_prefixes": ["component:"], "type_order": ["bug", "chore"]}`)
+
+	cfg, err := LoadClaimConfig(dbPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxInProgress != 2 {
+		t.Errorf("expected max_in_progress 2, got %d", cfg.MaxInProgress)
+	}
+	if cfg.LabelLimits["release"] != 1 {
+		t.Errorf("expected release limit 1, got %v", cfg.LabelLimits)
+	}
+	if len(cfg.ExclusivePrefixes) != 1 || cfg.ExclusivePrefixes[0] != "component:" {
+		t.Errorf("expected exclusive prefix component:, got %v", cfg.ExclusivePrefixes)
+	}
+	if len(cfg.TypeOrder) != 2 || cfg.TypeOrder[0] != "bug" {
+		t.Errorf("expected type order [bug chore], got %v", cfg.TypeOrder)
+	}
+}
+
+func TestLoadClaimConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"malformed", `{"max_in_progress": `},
+		{"unknown field", `{"max_in_progres": 2}`},
+		{"negative limit", `{"max_in_progress": -1}`},
+		{"negative label limit", `{"label_limits": {"release": -1}}`},
+		{"empty exclusive prefix", `{"exclusive_prefixes": [""]}`},
+		{"empty type in type order", `{"type_order": ["bug", ""]}`},
+		{"bad profile pattern", `{"profiles": {"fe-[": {}}}`},
+		{"bad profile expression", `{"profiles": {"fe-1": {"where": "label:frontend or"}}}`},
+		{"bad profile priority", `{"profiles": {"fe-1": {"max_priority": "P7"}}}`},
+		{"unknown profile field", `{"profiles": {"fe-1": {"label": ["frontend"]}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadClaimConfig(writeClaimConfig(t, t.TempDir(), tt.content))
+			claimErr, ok := err.(*domain.ClaimFailed)
+			if !ok || claimErr.ErrorCode != domain.ErrCodeInvalidArgument {
+				t.Errorf("expected INVALID_ARGUMENT error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClaimConfigLoader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	loader := NewClaimConfigLoader(filepath.Join(dir, "beads.db"))
+
+	if cfg, err := loader.Load(); err != nil || cfg.MaxInProgress != 0 {
+		t.Fatalf("expected zero config, got %+v (%v)", cfg, err)
+	}
+
+	writeClaimConfig(t, dir, `{"max_in_progress": 2}`)
+	if cfg, err := loader.Load(); err != nil || cfg.MaxInProgress != 2 {
+		t.Fatalf("expected max_in_progress 2, got %+v (%v)", cfg, err)
+	}
+
+	// Same size, newer mtime
+	path := writeClaimConfig(t, dir, `{"max_in_progress": 3}`)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(filepath.Dir(path), ClaimConfigFile), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := loader.Load(); err != nil || cfg.MaxInProgress != 3 {
+		t.Fatalf("expected max_in_progress 3, got %+v (%v)", cfg, err)
+	}
+
+	writeClaimConfig(t, dir, `{"max_in_progress": -1}`)
+	for i := 0; i < 2; i++ {
+		if _, err := loader.Load(); err == nil {
+			t.Fatal("expected an invalid config to keep failing")
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, ClaimConfigFile)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := loader.Load(); err != nil || cfg.MaxInProgress != 0 {
+		t.Fatalf("expected zero config once removed, got %+v (%v)", cfg, err)
+	}
+}
+
+func TestClaimProfiles_Match(t *testing.T) {
+	profiles := ClaimProfiles{
+		"fe-1": {Labels: []string{"frontend", "lead"}},
+		"fe-*": {Labels: []string{"frontend"}},
+		"f*":   {Labels: []string{"anything"}},
+	}
+
+	tests := []struct {
+		agent string
+		want  string
+	}{
+		{"fe-1", "fe-1"},
+		{"fe-2", "f*"}, // globs are tried in sorted order
+		{"go-1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agent, func(t *testing.T) {
+			name, _, ok := profiles.Match(tt.agent)
+			if ok != (tt.want != "") || name != tt.want {
+				t.Errorf("expected profile %q, got %q (found %v)", tt.want, name, ok)
+			}
+		})
+	}
+
+	if _, _, ok := ClaimProfiles(nil).Match("fe-1"); ok {
+		t.Error("expected no profile without profiles")
+	}
+}
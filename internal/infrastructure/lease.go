@@ -0,0 +1,240 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// leaseTable is the bd-claim-owned side table holding claim leases. It lives
+// in the Beads database so leases commit atomically with the claim itself.
+const leaseTable = "bd_claim_leases"
+
+// leaseTimeFormat is fixed-width UTC so lease timestamps compare correctly as text.
+const leaseTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// eventReclaimed marks a claim taken over from an agent whose lease expired.
+const eventReclaimed = "reclaimed"
+
+func formatLeaseTime(t time.Time) string {
+	return t.UTC().Format(leaseTimeFormat)
+}
+
+// ensureLeaseTable creates the lease side table if it does not exist yet.
+func ensureLeaseTable(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+leaseTable+` (
+			issue_id TEXT PRIMARY KEY,
+			agent TEXT NOT NULL,
+			claimed_at TEXT NOT NULL,
+			lease_seconds INTEGER NOT NULL,
+			expires_at TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to create lease table: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return nil
+}
+
+// readyCondition returns the predicate selecting claimable issues: open ones,
+// plus in-progress ones whose lease expired before now when leases are in use.
+func readyCondition(withLeases bool, now time.Time) (string, []interface{}) {
+	if !withLeases {
+		return "i.status = 'open'", nil
+	}
+
+	return `(i.status = 'open' OR (i.status = 'in_progress' AND EXISTS (
+			SELECT 1 FROM ` + leaseTable + ` l
+			WHERE l.issue_id = i.id AND l.agent = i.assignee AND l.expires_at < ?
+		)))`, []interface{}{formatLeaseTime(now)}
+}
+
+// saveLease records the lease for a fresh claim, replacing any previous one.
+// A zero lease removes any stale lease row so the claim never expires.
+func saveLease(ctx context.Context, tx *sql.Tx, issueID domain.IssueId, agent domain.AgentName, lease time.Duration, now time.Time) (*time.Time, error) {
+	if lease <= 0 {
+		return nil, deleteLease(ctx, tx, issueID)
+	}
+
+	if err := ensureLeaseTable(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(lease)
+	_, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO `+leaseTable+` (issue_id, agent, claimed_at, lease_seconds, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, issueID.String(), agent.String(), formatLeaseTime(now), int64(lease/time.Second), formatLeaseTime(expiresAt))
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to record lease: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return &expiresAt, nil
+}
+
+// deleteLease drops the lease row for an issue, if leases are in use at all.
+func deleteLease(ctx context.Context, tx *sql.Tx, issueID domain.IssueId) error {
+	ok, err := hasTable(ctx, tx, leaseTable)
+	if err != nil || !ok {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM `+leaseTable+` WHERE issue_id = ?`, issueID.String())
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to delete lease: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return nil
+}
+
+// reclaimEvents builds the audit events for an issue taken over from an agent
+// whose lease expired. The status stays in_progress; only ownership moves.
+func reclaimEvents(issueID domain.IssueId, agent, previous domain.AgentName) []beadsEvent {
+	return []beadsEvent{{
+		issueID:   issueID,
+		eventType: eventReclaimed,
+		actor:     agent,
+		oldValue:  nullString(previous.String()),
+		newValue:  nullString(agent.String()),
+		comment:   nullString("reclaimed from agent " + previous.String() + " after lease expired"),
+	}}
+}
+
+// heartbeatTable is the bd-claim-owned side table recording when each claim
+// holder last reported it was alive.
+const heartbeatTable = "bd_claim_heartbeats"
+
+// ensureHeartbeatTable creates the heartbeat side table if it does not exist yet.
+func ensureHeartbeatTable(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+heartbeatTable+` (
+			issue_id TEXT PRIMARY KEY,
+			agent TEXT NOT NULL,
+			last_heartbeat_at TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to create heartbeat table: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return nil
+}
+
+// saveHeartbeat records that the agent holding the issue is alive as of now.
+func saveHeartbeat(ctx context.Context, tx *sql.Tx, issueID domain.IssueId, agent domain.AgentName, now time.Time) error {
+	if err := ensureHeartbeatTable(ctx, tx); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO `+heartbeatTable+` (issue_id, agent, last_heartbeat_at)
+		VALUES (?, ?, ?)
+	`, issueID.String(), agent.String(), formatLeaseTime(now))
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to record heartbeat: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return nil
+}
+
+// deleteHeartbeat drops the heartbeat row for an issue so a new holder starts
+// with a clean slate.
+func deleteHeartbeat(ctx context.Context, tx *sql.Tx, issueID domain.IssueId) error {
+	ok, err := hasTable(ctx, tx, heartbeatTable)
+	if err != nil || !ok {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM `+heartbeatTable+` WHERE issue_id = ?`, issueID.String())
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to delete heartbeat: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return nil
+}
+
+// renewLease pushes an existing lease held by the agent out by its original
+// duration. It returns the new expiry, or nil if the claim has no lease.
+func renewLease(ctx context.Context, tx *sql.Tx, issueID domain.IssueId, agent domain.AgentName, now time.Time) (*time.Time, error) {
+	ok, err := hasTable(ctx, tx, leaseTable)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var leaseSeconds int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT lease_seconds FROM `+leaseTable+` WHERE issue_id = ? AND agent = ?
+	`, issueID.String(), agent.String()).Scan(&leaseSeconds)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to read lease: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	expiresAt := now.Add(time.Duration(leaseSeconds) * time.Second)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE `+leaseTable+` SET expires_at = ? WHERE issue_id = ?
+	`, formatLeaseTime(expiresAt), issueID.String())
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to renew lease: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return &expiresAt, nil
+}
+
+// transferLease moves a lease held by from over to to, restarting it for its
+// original duration. It returns the new expiry, or nil if the claim has no lease.
+func transferLease(ctx context.Context, tx *sql.Tx, issueID domain.IssueId, from, to domain.AgentName, now time.Time) (*time.Time, error) {
+	ok, err := hasTable(ctx, tx, leaseTable)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var leaseSeconds int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT lease_seconds FROM `+leaseTable+` WHERE issue_id = ? AND agent = ?
+	`, issueID.String(), from.String()).Scan(&leaseSeconds)
+	if err == sql.ErrNoRows {
+		return nil, deleteLease(ctx, tx, issueID)
+	}
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to read lease: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return saveLease(ctx, tx, issueID, to, time.Duration(leaseSeconds)*time.Second, now)
+}
@@ -0,0 +1,280 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// expireLease moves an issue's lease expiry into the past.
+func expireLease(t *testing.T, dbPath, issueID string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	past := formatLeaseTime(time.Now().Add(-time.Minute))
+	if _, err := db.Exec(`UPDATE `+leaseTable+` SET expires_at = ? WHERE issue_id = ?`, past, issueID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countLeases(t *testing.T, dbPath string) int {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + leaseTable).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestReclaimEvents(t *testing.T) {
+	events := reclaimEvents("issue-1", "agent-2", "agent-1")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.eventType != eventReclaimed || e.oldValue.String != "agent-1" || e.newValue.String != "agent-2" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e.comment.String != "reclaimed from agent agent-1 after lease expired" {
+		t.Errorf("unexpected comment: %q", e.comment.String)
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimWithLease(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	before := time.Now()
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-1", domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil || issue.LeaseExpiresAt == nil {
+		t.Fatalf("expected leased issue, got %+v", issue)
+	}
+	if issue.LeaseExpiresAt.Before(before.Add(time.Hour)) {
+		t.Errorf("expected lease to expire in an hour, got %s", issue.LeaseExpiresAt)
+	}
+	if issue.ReclaimedFrom != nil {
+		t.Error("expected fresh claim, not a reclaim")
+	}
+
+	// An unexpired lease keeps the issue out of the ready pool
+	other, err := repo.ClaimOneReadyIssue(context.Background(), "agent-2", domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other != nil {
+		t.Errorf("expected no issue while lease is live, got %s", other.ID)
+	}
+}
+
+func TestSQLiteIssueRepository_ReclaimExpiredLease(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	addBeadsAuditTables(t, dbPath)
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	if _, err := repo.ClaimOneReadyIssue(ctx, "agent-1", domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	expireLease(t, dbPath, "issue-1")
+
+	// Dry-run sees the expired claim as ready
+	found, err := repo.FindOneReadyIssue(ctx, domain.NewClaimFilters())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found == nil || found.ID != "issue-1" {
+		t.Fatalf("expected issue-1 to be ready, got %+v", found)
+	}
+
+	issue, err := repo.ClaimOneReadyIssue(ctx, "agent-2", domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil || issue.ID != "issue-1" {
+		t.Fatalf("expected issue-1 to be reclaimed, got %+v", issue)
+	}
+	if issue.Assignee == nil || *issue.Assignee != "agent-2" {
+		t.Errorf("expected assignee agent-2, got %v", issue.Assignee)
+	}
+	if issue.ReclaimedFrom == nil || *issue.ReclaimedFrom != "agent-1" {
+		t.Errorf("expected reclaimed from agent-1, got %v", issue.ReclaimedFrom)
+	}
+
+	events := fetchTestEvents(t, dbPath, "issue-1")
+	last := events[len(events)-1]
+	if last.eventType != eventReclaimed || last.actor != "agent-2" || last.oldValue.String != "agent-1" {
+		t.Errorf("unexpected reclaim event: %+v", last)
+	}
+
+	// Claiming without a lease leaves no lease behind
+	if n := countLeases(t, dbPath); n != 0 {
+		t.Errorf("expected stale lease to be removed, got %d rows", n)
+	}
+}
+
+func TestSQLiteIssueRepository_ReleaseDeletesLease(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	if _, err := repo.ClaimOneReadyIssue(ctx, "agent-1", domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReleaseIssue(ctx, "agent-1", "issue-1", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countLeases(t, dbPath); n != 0 {
+		t.Errorf("expected lease to be removed on release, got %d rows", n)
+	}
+}
+
+func TestSQLiteIssueRepository_Heartbeat(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	claimed, err := repo.ClaimOneReadyIssue(ctx, "agent-1", domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expireLease(t, dbPath, "issue-1")
+
+	// A heartbeat before anyone reclaims the issue renews the lapsed lease
+	issue, err := repo.Heartbeat(ctx, "agent-1", "issue-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.LastHeartbeatAt == nil {
+		t.Error("expected last heartbeat to be set")
+	}
+	if issue.LeaseExpiresAt == nil || !issue.LeaseExpiresAt.After(*claimed.LeaseExpiresAt) {
+		t.Errorf("expected lease to be renewed past %s, got %v", claimed.LeaseExpiresAt, issue.LeaseExpiresAt)
+	}
+
+	other, err := repo.ClaimOneReadyIssue(ctx, "agent-2", domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other != nil {
+		t.Errorf("expected renewed lease to keep issue claimed, got %s", other.ID)
+	}
+}
+
+func TestSQLiteIssueRepository_Heartbeat_NoLease(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	if _, err := repo.ClaimOneReadyIssue(ctx, "agent-1", domain.NewClaimFilters(), domain.ClaimOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	issue, err := repo.Heartbeat(ctx, "agent-1", "issue-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.LastHeartbeatAt == nil || issue.LeaseExpiresAt != nil {
+		t.Errorf("expected heartbeat without lease, got %+v", issue)
+	}
+}
+
+func TestSQLiteIssueRepository_Heartbeat_LeaseLost(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	if _, err := repo.ClaimOneReadyIssue(ctx, "agent-1", domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	expireLease(t, dbPath, "issue-1")
+	if _, err := repo.ClaimOneReadyIssue(ctx, "agent-2", domain.NewClaimFilters(), domain.ClaimOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		agent   domain.AgentName
+		issueID domain.IssueId
+		code    domain.ClaimErrorCode
+	}{
+		{"reclaimed by another agent", "agent-1", "issue-1", domain.ErrCodeLeaseLost},
+		{"missing issue", "agent-2", "missing", domain.ErrCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.Heartbeat(ctx, tt.agent, tt.issueID)
+			claimErr, ok := err.(*domain.ClaimFailed)
+			if !ok || claimErr.ErrorCode != tt.code {
+				t.Errorf("expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+}
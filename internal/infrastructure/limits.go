@@ -0,0 +1,229 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// labelLimitCondition returns the WHERE fragment skipping issues that carry a
+// label already at its swarm-wide in-progress limit. The issue itself is not
+// counted, so an expired-lease issue can still be reclaimed at the limit.
+func labelLimitCondition(limits map[string]int) (string, []interface{}) {
+	var condition string
+	var args []interface{}
+
+	for _, label := range sortedLabels(limits) {
+		condition += ` AND NOT (
+			EXISTS (SELECT 1 FROM labels l WHERE l.issue_id = i.id AND l.label = ?)
+			AND (SELECT COUNT(*) FROM issues w JOIN labels wl ON wl.issue_id = w.id
+				WHERE wl.label = ? AND w.status = 'in_progress' AND w.id != i.id) >= ?
+		)`
+		args = append(args, label, label, limits[label])
+	}
+
+	return condition, args
+}
+
+func sortedLabels(limits map[string]int) []string {
+	labels := make([]string, 0, len(limits))
+	for label := range limits {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// labelBudget is how many more issues may go in progress under each limited
+// label. Claiming several issues in one transaction draws it down, so a batch
+// cannot overshoot a limit the ready query checked issue by issue.
+type labelBudget map[string]int
+
+// newLabelBudget counts the issues in progress under each limited label.
+func newLabelBudget(ctx context.Context, q queryRower, limits map[string]int) (labelBudget, error) {
+	budget := make(labelBudget, len(limits))
+	for label, limit := range limits {
+		var count int
+		err := q.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM issues w JOIN labels wl ON wl.issue_id = w.id
+			WHERE wl.label = ? AND w.status = 'in_progress'
+		`, label).Scan(&count)
+		if err != nil {
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to count issues in progress: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		budget[label] = limit - count
+	}
+	return budget, nil
+}
+
+// admit reports whether an open issue with these labels fits the remaining
+// budget, and if so takes its share.
+func (b labelBudget) admit(labels domain.LabelSet) bool {
+	for _, label := range labels {
+		if remaining, ok := b[label]; ok && remaining <= 0 {
+			return false
+		}
+	}
+	for _, label := range labels {
+		if _, ok := b[label]; ok {
+			b[label]--
+		}
+	}
+	return true
+}
+
+// labelAtLimit returns a label of the issue that is at its in-progress limit,
+// not counting the issue itself, or "" if there is none.
+func labelAtLimit(ctx context.Context, tx *sql.Tx, limits map[string]int, issue *domain.Issue) (string, error) {
+	for _, label := range sortedLabels(limits) {
+		if !issue.Labels.Contains(label) {
+			continue
+		}
+
+		var count int
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM issues w JOIN labels wl ON wl.issue_id = w.id
+			WHERE wl.label = ? AND w.status = 'in_progress' AND w.id != ?
+		`, label, issue.ID.String()).Scan(&count)
+		if err != nil {
+			return "", &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to count issues in progress: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		if count >= limits[label] {
+			return label, nil
+		}
+	}
+	return "", nil
+}
+
+// exclusiveCondition returns the WHERE fragment skipping issues that share a
+// label under one of the exclusive prefixes with another issue in progress.
+func exclusiveCondition(prefixes []string) (string, []interface{}) {
+	var condition string
+	var args []interface{}
+
+	for _, prefix := range prefixes {
+		condition += ` AND NOT EXISTS (
+			SELECT 1 FROM labels l
+			JOIN labels wl ON wl.label = l.label AND wl.issue_id != i.id
+			JOIN issues w ON w.id = wl.issue_id AND w.status = 'in_progress'
+			WHERE l.issue_id = i.id AND substr(l.label, 1, length(?)) = ?
+		)`
+		args = append(args, prefix, prefix)
+	}
+
+	return condition, args
+}
+
+// exclusiveKeys are the exclusive-group labels of issues claimed earlier in
+// the same transaction, which the ready query ran too early to see.
+type exclusiveKeys map[string]bool
+
+// conflicts reports whether any of these exclusive-group labels is taken.
+func (k exclusiveKeys) conflicts(labels domain.LabelSet) bool {
+	for _, label := range labels {
+		if k[label] {
+			return true
+		}
+	}
+	return false
+}
+
+// take marks the labels as taken by an issue claimed in this transaction.
+func (k exclusiveKeys) take(labels domain.LabelSet) {
+	for _, label := range labels {
+		k[label] = true
+	}
+}
+
+// exclusiveConflict returns a label of the issue under one of the exclusive
+// prefixes together with another in-progress issue carrying it, or "" if the
+// issue conflicts with nothing.
+func exclusiveConflict(
+	ctx context.Context,
+	tx *sql.Tx,
+	prefixes []string,
+	issue *domain.Issue,
+) (string, domain.IssueId, error) {
+	for _, label := range issue.Labels.WithPrefix(prefixes) {
+		var holder domain.IssueId
+		err := tx.QueryRowContext(ctx, `
+			SELECT w.id FROM issues w JOIN labels wl ON wl.issue_id = w.id
+			WHERE wl.label = ? AND w.status = 'in_progress' AND w.id != ?
+			ORDER BY w.id LIMIT 1
+		`, label, issue.ID.String()).Scan(&holder)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return "", "", &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to find conflicting issues: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		return label, holder, nil
+	}
+	return "", "", nil
+}
+
+// batchAdmission picks the issues a batch claim takes from the ready query's
+// rows. The query checks label limits and exclusive groups issue by issue, so
+// the batch draws down a labelBudget and tracks its exclusiveKeys as it goes.
+// A nil batchAdmission takes every row.
+type batchAdmission struct {
+	prefixes []string
+	budget   labelBudget
+	keys     exclusiveKeys
+}
+
+// newBatchAdmission returns the admission for a batch of n issues, or nil when
+// the ready query alone decides: for a single issue, or when filters set no
+// label limits or exclusive prefixes.
+func newBatchAdmission(ctx context.Context, q queryRower, filters domain.ClaimFilters, n int) (*batchAdmission, error) {
+	if n <= 1 || (len(filters.LabelLimits) == 0 && len(filters.ExclusivePrefixes) == 0) {
+		return nil, nil
+	}
+
+	a := &batchAdmission{prefixes: filters.ExclusivePrefixes}
+	if len(filters.LabelLimits) > 0 {
+		budget, err := newLabelBudget(ctx, q, filters.LabelLimits)
+		if err != nil {
+			return nil, err
+		}
+		a.budget = budget
+	}
+	if len(filters.ExclusivePrefixes) > 0 {
+		a.keys = exclusiveKeys{}
+	}
+	return a, nil
+}
+
+// admit reports whether the batch takes an issue with these labels and
+// status, and if so counts the issue against the batch.
+func (a *batchAdmission) admit(labels domain.LabelSet, status string) bool {
+	if a == nil {
+		return true
+	}
+	exclusive := labels.WithPrefix(a.prefixes)
+	if a.keys.conflicts(exclusive) {
+		return false
+	}
+	// Reclaimed issues are already counted as in progress
+	if a.budget != nil && status == string(domain.StatusOpen) && !a.budget.admit(labels) {
+		return false
+	}
+	if a.keys != nil {
+		a.keys.take(exclusive)
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package infrastructure
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+func TestLabelBudget_Admit(t *testing.T) {
+	budget := labelBudget{"release": 1, "db-migration": 0}
+
+	if budget.admit(domain.LabelSet{"release", "db-migration"}) {
+		t.Error("expected an issue with an exhausted label to be refused")
+	}
+	if !budget.admit(domain.LabelSet{"release", "backend"}) {
+		t.Error("expected an issue within budget to be admitted")
+	}
+	if budget.admit(domain.LabelSet{"release"}) {
+		t.Error("expected the admitted issue to use up the release budget")
+	}
+	if !budget.admit(domain.LabelSet{"backend"}) {
+		t.Error("expected unlimited labels to be admitted")
+	}
+}
+
+func TestSQLiteIssueRepository_LabelLimits(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	holder := "agent-x"
+	insertTestIssue(t, dbPath, "held", "Held migration", "in_progress", 1, &holder)
+	insertTestLabel(t, dbPath, "held", "db-migration")
+	insertTestIssue(t, dbPath, "mig-1", "Migration", "open", 0, nil)
+	insertTestLabel(t, dbPath, "mig-1", "db-migration")
+	insertTestIssue(t, dbPath, "other-1", "Other", "open", 1, nil)
+	insertTestIssue(t, dbPath, "rel-1", "Release one", "open", 2, nil)
+	insertTestLabel(t, dbPath, "rel-1", "release")
+	insertTestIssue(t, dbPath, "rel-2", "Release two", "open", 3, nil)
+	insertTestLabel(t, dbPath, "rel-2", "release")
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.LabelLimits = map[string]int{"db-migration": 1, "release": 1}
+
+	// mig-1 is most urgent but db-migration is fully staffed
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-a", filters, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil || issue.ID != "other-1" {
+		t.Fatalf("expected other-1 to be claimed, got %v", issue)
+	}
+
+	_, err = repo.ClaimIssueByID(context.Background(), "agent-a", "mig-1", filters, domain.ClaimOptions{})
+	claimErr, ok := err.(*domain.ClaimFailed)
+	if !ok || claimErr.ErrorCode != domain.ErrCodeNotReady || !strings.Contains(claimErr.Message, "db-migration") {
+		t.Errorf("expected NOT_READY naming the label, got %v", err)
+	}
+
+	// Both release issues are individually ready, but a batch may take only one
+	issues, err := repo.ClaimReadyIssues(context.Background(), "agent-b", filters, 5, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != "rel-1" {
+		t.Fatalf("expected only rel-1 to be claimed, got %v", issues)
+	}
+
+	if issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-c", filters, domain.ClaimOptions{}); err != nil || issue != nil {
+		t.Errorf("expected nothing claimable, got %v, %v", issue, err)
+	}
+
+	// Without the limits the remaining issues are ready as usual
+	if found, err := repo.FindReadyIssues(context.Background(), domain.NewClaimFilters(), 5); err != nil || len(found) != 2 {
+		t.Errorf("expected mig-1 and rel-2 to be ready without limits, got %v, %v", found, err)
+	}
+}
+
+func TestSQLiteIssueRepository_LabelLimits_ReclaimAtLimit(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "rel-1", "Release", "open", 1, nil)
+	insertTestLabel(t, dbPath, "rel-1", "release")
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.LabelLimits = map[string]int{"release": 1}
+
+	if _, err := repo.ClaimOneReadyIssue(context.Background(), "agent-a", filters, domain.ClaimOptions{Lease: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	expireLease(t, dbPath, "rel-1")
+
+	// The abandoned issue is the one holding the limit, so it can be taken over
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-b", filters, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil || issue.ID != "rel-1" || issue.ReclaimedFrom == nil {
+		t.Errorf("expected rel-1 to be reclaimed, got %v", issue)
+	}
+}
+
+func TestSQLiteIssueRepository_BatchDryRunMatchesClaim(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "rel-1", "Release one", "open", 1, nil)
+	insertTestLabel(t, dbPath, "rel-1", "release")
+	insertTestIssue(t, dbPath, "rel-2", "Release two", "open", 2, nil)
+	insertTestLabel(t, dbPath, "rel-2", "release")
+	insertTestIssue(t, dbPath, "api-1", "API one", "open", 1, nil)
+	insertTestLabel(t, dbPath, "api-1", "component:api")
+	insertTestIssue(t, dbPath, "api-2", "API two", "open", 2, nil)
+	insertTestLabel(t, dbPath, "api-2", "component:api")
+	insertTestIssue(t, dbPath, "cli-1", "CLI", "open", 3, nil)
+	insertTestLabel(t, dbPath, "cli-1", "component:cli")
+	insertTestIssue(t, dbPath, "other-1", "Other", "open", 4, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.LabelLimits = map[string]int{"release": 1}
+	filters.ExclusivePrefixes = []string{"component:"}
+
+	found, err := repo.FindReadyIssues(context.Background(), filters, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claimed, err := repo.ClaimReadyIssues(context.Background(), "agent-a", filters, 5, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := func(issues []*domain.Issue) string {
+		var s []string
+		for _, issue := range issues {
+			s = append(s, issue.ID.String())
+		}
+		return strings.Join(s, ",")
+	}
+	// rel-2 is over the release limit and api-2 shares api-1's key
+	if ids(found) != ids(claimed) || len(claimed) != 4 || strings.Contains(ids(claimed), "-2") {
+		t.Errorf("expected the dry run to list what the batch claims, got %s and %s", ids(found), ids(claimed))
+	}
+}
+
+func TestExclusiveKeys(t *testing.T) {
+	keys := exclusiveKeys{}
+
+	if keys.conflicts(domain.LabelSet{"component:api"}) {
+		t.Error("expected no conflict before anything is taken")
+	}
+	keys.take(domain.LabelSet{"component:api"})
+	if !keys.conflicts(domain.LabelSet{"component:cli", "component:api"}) {
+		t.Error("expected a conflict on a taken label")
+	}
+	if keys.conflicts(domain.LabelSet{"component:cli"}) {
+		t.Error("expected other labels in the group to be free")
+	}
+}
+
+func TestSQLiteIssueRepository_ExclusivePrefixes(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	holder := "agent-x"
+	insertTestIssue(t, dbPath, "held", "Held API work", "in_progress", 1, &holder)
+	insertTestLabel(t, dbPath, "held", "component:api")
+	insertTestIssue(t, dbPath, "api-1", "API work", "open", 0, nil)
+	insertTestLabel(t, dbPath, "api-1", "component:api")
+	insertTestIssue(t, dbPath, "cli-1", "CLI work", "open", 1, nil)
+	insertTestLabel(t, dbPath, "cli-1", "component:cli")
+	insertTestIssue(t, dbPath, "cli-2", "More CLI work", "open", 2, nil)
+	insertTestLabel(t, dbPath, "cli-2", "component:cli")
+	insertTestIssue(t, dbPath, "docs-1", "Docs", "open", 3, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.ExclusivePrefixes = []string{"component:"}
+
+	_, err = repo.ClaimIssueByID(context.Background(), "agent-a", "api-1", filters, domain.ClaimOptions{})
+	claimErr, ok := err.(*domain.ClaimFailed)
+	if !ok || claimErr.ErrorCode != domain.ErrCodeNotReady || !strings.Contains(claimErr.Message, "component:api") || !strings.Contains(claimErr.Message, "held") {
+		t.Errorf("expected NOT_READY naming the label and the held issue, got %v", err)
+	}
+
+	// api-1 is most urgent but conflicts with held; cli-1 and cli-2 are both
+	// ready but conflict with each other, so a batch takes only one
+	issues, err := repo.ClaimReadyIssues(context.Background(), "agent-a", filters, 5, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []domain.IssueId
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	if len(ids) != 2 || ids[0] != "cli-1" || ids[1] != "docs-1" {
+		t.Fatalf("expected cli-1 and docs-1 to be claimed, got %v", ids)
+	}
+
+	if issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-b", filters, domain.ClaimOptions{}); err != nil || issue != nil {
+		t.Errorf("expected nothing claimable, got %v, %v", issue, err)
+	}
+
+	// Without the prefix the groups mean nothing
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-b", domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil || issue == nil || issue.ID != "api-1" {
+		t.Errorf("expected api-1 to be claimed without exclusive groups, got %v, %v", issue, err)
+	}
+}
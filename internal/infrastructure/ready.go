@@ -0,0 +1,326 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// blockedCacheTable is the table Beads maintains with every blocked issue id.
+const blockedCacheTable = "blocked_issues_cache"
+
+// blockedByDependencies mirrors the Beads ready_issues view: an issue is
+// blocked by an unfinished 'blocks' dependency, and blockage flows down
+// 'parent-child' edges to every descendant.
+const blockedByDependencies = `
+	blocked_directly AS (
+		SELECT DISTINCT d.issue_id
+		FROM dependencies d
+		JOIN issues blocker ON d.depends_on_id = blocker.id
+		WHERE d.type = 'blocks'
+		AND blocker.status IN ('open', 'in_progress', 'blocked')
+	),
+	blocked_transitively AS (
+		SELECT issue_id, 0 AS depth
+		FROM blocked_directly
+		UNION ALL
+		SELECT d.issue_id, bt.depth + 1
+		FROM blocked_transitively bt
+		JOIN dependencies d ON d.depends_on_id = bt.issue_id
+		WHERE d.type = 'parent-child'
+		AND bt.depth < 50
+	)`
+
+// readinessSQL is the SQL that implements a resolved readiness strategy.
+type readinessSQL struct {
+	strategy domain.ReadinessStrategy
+	// ctes holds common table expressions the blocked source depends on.
+	ctes []string
+	// blocked names the table or CTE listing blocked issue ids. Empty means
+	// nothing can block an issue.
+	blocked string
+}
+
+// resolveReadiness picks the SQL for a readiness strategy, falling back to the
+// dependency graph when auto finds no blocked_issues_cache.
+func resolveReadiness(ctx context.Context, q queryRower, strategy domain.ReadinessStrategy) (readinessSQL, error) {
+	hasCache, err := hasTable(ctx, q, blockedCacheTable)
+	if err != nil {
+		return readinessSQL{}, err
+	}
+
+	switch strategy {
+	case "", domain.ReadinessAuto:
+		if hasCache {
+			return readinessSQL{strategy: domain.ReadinessCache, blocked: blockedCacheTable}, nil
+		}
+	case domain.ReadinessCache:
+		if !hasCache {
+			return readinessSQL{}, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeSchemaIncompatible,
+				Message:    "readiness strategy 'cache' requires the blocked_issues_cache table",
+				OccurredAt: domain.Now(),
+			}
+		}
+		return readinessSQL{strategy: domain.ReadinessCache, blocked: blockedCacheTable}, nil
+	case domain.ReadinessDependencies:
+	default:
+		return readinessSQL{}, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeInvalidArgument,
+			Message:    fmt.Sprintf("unknown readiness strategy %q", strategy),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	// Without a dependencies table nothing can block an issue
+	hasDependencies, err := hasTable(ctx, q, "dependencies")
+	if err != nil {
+		return readinessSQL{}, err
+	}
+	if !hasDependencies {
+		return readinessSQL{strategy: domain.ReadinessDependencies}, nil
+	}
+	return readinessSQL{
+		strategy: domain.ReadinessDependencies,
+		ctes:     []string{blockedByDependencies},
+		blocked:  "blocked_transitively",
+	}, nil
+}
+
+// withClause joins common table expressions into a WITH RECURSIVE prefix.
+func withClause(ctes []string) string {
+	if len(ctes) == 0 {
+		return ""
+	}
+	return "WITH RECURSIVE " + strings.Join(ctes, ",\n")
+}
+
+// readySelect builds a query returning columns for claimable issues that
+// match filters, in claim order, followed by the score column described by
+// scoreColumn. The query ends in a LIMIT placeholder the caller must supply.
+// A non-empty onlyID restricts the query to that issue.
+func (r *SQLiteIssueRepository) readySelect(
+	ctx context.Context,
+	q queryRower,
+	columns string,
+	filters domain.ClaimFilters,
+	onlyID domain.IssueId,
+	now time.Time,
+) (string, []interface{}, error) {
+	readiness, err := resolveReadiness(ctx, q, filters.Readiness)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkParent(ctx, q, filters.Parent); err != nil {
+		return "", nil, err
+	}
+	criticalPath, err := usesCriticalPath(ctx, q, filters)
+	if err != nil {
+		return "", nil, err
+	}
+	// Issues held under an expired lease are claimable alongside open ones
+	withLeases, err := hasTable(ctx, q, leaseTable)
+	if err != nil {
+		return "", nil, err
+	}
+	readyClause, args := readyCondition(withLeases, now)
+
+	blockedClause := ""
+	if readiness.blocked != "" {
+		blockedClause = "AND NOT EXISTS (SELECT 1 FROM " + readiness.blocked + " bl WHERE bl.issue_id = i.id)"
+	}
+
+	// Build the WHERE clause based on filters
+	whereClause, filterArgs := r.buildWhereClause(filters)
+	args = append(args, filterArgs...)
+	if !onlyID.IsEmpty() {
+		whereClause += " AND i.id = ?"
+		args = append(args, onlyID.String())
+	}
+
+	candidates := fmt.Sprintf(`
+	candidates(id) AS (
+		SELECT i.id
+		FROM issues i
+		WHERE %s
+		%s
+		%s
+	)`, readyClause, blockedClause, whereClause)
+	ctes := append(slices.Clip(readiness.ctes), candidates)
+
+	// The critical-path walk starts from the candidates only
+	scoreJoin := ""
+	if criticalPath {
+		ctes = append(ctes, dependentsCTE)
+		scoreJoin = "LEFT JOIN scores s ON s.id = i.id"
+	}
+
+	orderClause, orderArgs := orderBy(filters, criticalPath)
+	args = append(args, orderArgs...)
+
+	query := fmt.Sprintf(`
+		%s
+		SELECT %s, %s
+		FROM candidates c
+		JOIN issues i ON i.id = c.id
+		%s
+		ORDER BY %s
+		LIMIT ?
+	`, withClause(ctes), columns, scoreColumn(filters, criticalPath), scoreJoin, orderClause)
+
+	return query, args, nil
+}
+
+// orderBy returns the claim order: most urgent first, then issues carrying
+// more preferred labels, then by issue type, then oldest first. Under the
+// critical-path strategy the issues unblocking the most work come before all
+// of that; the query must then join the scores from dependentsCTE as s.
+func orderBy(filters domain.ClaimFilters, criticalPath bool) (string, []interface{}) {
+	var order []string
+	var args []interface{}
+
+	if criticalPath {
+		order = append(order, unblocksScore+" DESC")
+	}
+	order = append(order, "i.priority ASC")
+
+	if len(filters.PreferLabels) > 0 {
+		placeholders, labelArgs := inList(filters.PreferLabels)
+		order = append(order, "(SELECT COUNT(*) FROM labels pl WHERE pl.issue_id = i.id AND pl.label IN ("+placeholders+")) DESC")
+		args = append(args, labelArgs...)
+	}
+
+	// Unlisted and missing types rank last
+	typeOrder := filters.TypeOrder
+	if typeOrder == nil {
+		typeOrder = domain.DefaultTypeOrder
+	}
+	if len(typeOrder) > 0 {
+		rank := "CASE i.issue_type"
+		for n, issueType := range typeOrder {
+			rank += " WHEN ? THEN " + strconv.Itoa(n)
+			args = append(args, issueType)
+		}
+		order = append(order, rank+" ELSE "+strconv.Itoa(len(typeOrder))+" END ASC")
+	}
+
+	order = append(order, "i.created_at ASC", "i.id ASC")
+	return strings.Join(order, ", "), args
+}
+
+// inList returns placeholders and arguments for an SQL IN list of values.
+func inList(values []string) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
+}
+
+// subtreeQuery returns a query selecting the ids of the parent's descendants
+// through parent-child dependencies, or only its children if direct. Like
+// blocked_transitively it stops 50 levels down, so a cycle cannot loop.
+func subtreeQuery(parent domain.IssueId, direct bool) (string, []interface{}) {
+	args := []interface{}{parent.String()}
+	if direct {
+		return `SELECT pc.issue_id AS id FROM dependencies pc
+			WHERE pc.depends_on_id = ? AND pc.type = 'parent-child'`, args
+	}
+	return `WITH RECURSIVE subtree(id, depth) AS (
+			SELECT pc.issue_id, 1 FROM dependencies pc
+			WHERE pc.depends_on_id = ? AND pc.type = 'parent-child'
+			UNION
+			SELECT pc.issue_id, s.depth + 1 FROM subtree s
+			JOIN dependencies pc ON pc.depends_on_id = s.id
+			WHERE pc.type = 'parent-child' AND s.depth < 50
+		)
+		SELECT id FROM subtree`, args
+}
+
+// checkParent fails unless the parent scoping a claim exists and the database
+// records the dependencies that make up its subtree. An empty parent passes.
+func checkParent(ctx context.Context, q queryRower, parent domain.IssueId) error {
+	if parent.IsEmpty() {
+		return nil
+	}
+
+	hasDependencies, err := hasTable(ctx, q, "dependencies")
+	if err != nil {
+		return err
+	}
+	if !hasDependencies {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSchemaIncompatible,
+			Message:    "scoping claims to a parent requires the dependencies table",
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	var count int
+	err = q.QueryRowContext(ctx, `SELECT COUNT(*) FROM issues WHERE id = ?`, parent.String()).Scan(&count)
+	if err != nil {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to look up parent issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	if count == 0 {
+		return &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeNotFound,
+			Message:    "parent issue " + parent.String() + " not found",
+			OccurredAt: domain.Now(),
+		}
+	}
+	return nil
+}
+
+// inSubtree reports whether the issue lies in the subtree filters scope claims
+// to. It is true when filters name no parent.
+func inSubtree(ctx context.Context, q queryRower, filters domain.ClaimFilters, issueID domain.IssueId) (bool, error) {
+	if filters.Parent.IsEmpty() {
+		return true, nil
+	}
+
+	subtree, args := subtreeQuery(filters.Parent, filters.DirectChildren)
+	var count int
+	err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM (`+subtree+`) WHERE id = ?`, append(args, issueID.String())...).Scan(&count)
+	if err != nil {
+		return false, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to walk parent subtree: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return count > 0, nil
+}
+
+// isBlocked reports whether the readiness strategy in filters considers the
+// issue blocked.
+func isBlocked(ctx context.Context, q queryRower, filters domain.ClaimFilters, issueID domain.IssueId) (bool, error) {
+	readiness, err := resolveReadiness(ctx, q, filters.Readiness)
+	if err != nil {
+		return false, err
+	}
+	if readiness.blocked == "" {
+		return false, nil
+	}
+
+	query := withClause(readiness.ctes) + `
+		SELECT COUNT(*) FROM ` + readiness.blocked + ` WHERE issue_id = ?`
+
+	var n int
+	if err := q.QueryRowContext(ctx, query, issueID.String()).Scan(&n); err != nil {
+		return false, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to check blocked state: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	return n > 0, nil
+}
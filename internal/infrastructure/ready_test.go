@@ -0,0 +1,357 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// execTestSQL runs statements against a test database.
+func execTestSQL(t *testing.T, dbPath, query string, args ...interface{}) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addDependenciesTable adds the Beads dependencies table that setupTestDB omits.
+func addDependenciesTable(t *testing.T, dbPath string) {
+	t.Helper()
+
+	execTestSQL(t, dbPath, `
+		CREATE TABLE dependencies (
+			issue_id TEXT NOT NULL,
+			depends_on_id TEXT NOT NULL,
+			type TEXT NOT NULL DEFAULT 'blocks',
+			PRIMARY KEY (issue_id, depends_on_id)
+		)
+	`)
+}
+
+func insertTestDependency(t *testing.T, dbPath, issueID, dependsOnID, depType string) {
+	t.Helper()
+
+	execTestSQL(t, dbPath, `INSERT INTO dependencies (issue_id, depends_on_id, type) VALUES (?, ?, ?)`,
+		issueID, dependsOnID, depType)
+}
+
+// setupDependencyFixture builds a graph where blocked_issues_cache is stale:
+//
+//	blocker (open)  --blocks-->        epic
+//	epic            --parent-child-->  child
+//	done (closed)   --blocks-->        unblocked
+//
+// Only 'unblocked' and 'blocker' are ready by the dependency graph, but the
+// cache is empty so it claims everything open.
+func setupDependencyFixture(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dbPath, cleanup := setupTestDB(t)
+	addDependenciesTable(t, dbPath)
+
+	insertTestIssue(t, dbPath, "epic", "Epic", "open", 0, nil)
+	insertTestIssue(t, dbPath, "child", "Child", "open", 1, nil)
+	insertTestIssue(t, dbPath, "unblocked", "Unblocked", "open", 2, nil)
+	insertTestIssue(t, dbPath, "blocker", "Blocker", "open", 3, nil)
+	insertTestIssue(t, dbPath, "done", "Done", "closed", 3, nil)
+
+	insertTestDependency(t, dbPath, "epic", "blocker", "blocks")
+	insertTestDependency(t, dbPath, "child", "epic", "parent-child")
+	insertTestDependency(t, dbPath, "unblocked", "done", "blocks")
+
+	return dbPath, cleanup
+}
+
+func readyIDs(t *testing.T, repo *SQLiteIssueRepository, filters domain.ClaimFilters) []string {
+	t.Helper()
+
+	issues, err := repo.FindReadyIssues(context.Background(), filters, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, issue := range issues {
+		ids = append(ids, issue.ID.String())
+	}
+	return ids
+}
+
+func TestResolveReadiness(t *testing.T) {
+	withCache, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	withoutCache, cleanup2 := setupTestDB(t)
+	defer cleanup2()
+	execTestSQL(t, withoutCache, `DROP TABLE blocked_issues_cache`)
+	addDependenciesTable(t, withoutCache)
+
+	tests := []struct {
+		name     string
+		dbPath   string
+		strategy domain.ReadinessStrategy
+		expected domain.ReadinessStrategy
+		errCode  domain.ClaimErrorCode
+	}{
+		{"auto with cache", withCache, domain.ReadinessAuto, domain.ReadinessCache, ""},
+		{"empty with cache", withCache, "", domain.ReadinessCache, ""},
+		{"auto without cache", withoutCache, domain.ReadinessAuto, domain.ReadinessDependencies, ""},
+		{"forced dependencies", withCache, domain.ReadinessDependencies, domain.ReadinessDependencies, ""},
+		{"forced cache without cache", withoutCache, domain.ReadinessCache, "", domain.ErrCodeSchemaIncompatible},
+		{"unknown", withCache, "graph", "", domain.ErrCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("sqlite3", tt.dbPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			readiness, err := resolveReadiness(context.Background(), db, tt.strategy)
+			if tt.errCode != "" {
+				claimErr, ok := err.(*domain.ClaimFailed)
+				if !ok || claimErr.ErrorCode != tt.errCode {
+					t.Errorf("expected %s error, got %v", tt.errCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if readiness.strategy != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, readiness.strategy)
+			}
+		})
+	}
+}
+
+func TestReadiness_CacheStrategy(t *testing.T) {
+	dbPath, cleanup := setupDependencyFixture(t)
+	defer cleanup()
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	// The stale cache blocks nothing, so every open issue is ready
+	ids := readyIDs(t, repo, domain.ClaimFilters{Readiness: domain.ReadinessCache})
+	expected := []string{"epic", "child", "unblocked", "blocker"}
+	if len(ids) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, ids)
+			break
+		}
+	}
+
+	// Once the cache is populated it is honoured
+	blockIssue(t, dbPath, "epic")
+	ids = readyIDs(t, repo, domain.ClaimFilters{Readiness: domain.ReadinessCache})
+	if len(ids) != 3 || ids[0] != "child" {
+		t.Errorf("expected epic to be skipped, got %v", ids)
+	}
+}
+
+func TestReadiness_DependenciesStrategy(t *testing.T) {
+	dbPath, cleanup := setupDependencyFixture(t)
+	defer cleanup()
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.ClaimFilters{Readiness: domain.ReadinessDependencies}
+
+	// epic is blocked directly, child inherits it through parent-child, and
+	// a closed blocker does not block
+	ids := readyIDs(t, repo, filters)
+	if len(ids) != 2 || ids[0] != "unblocked" || ids[1] != "blocker" {
+		t.Fatalf("expected [unblocked blocker], got %v", ids)
+	}
+
+	agent, _ := domain.NewAgentName("test-agent")
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil || issue.ID != "unblocked" {
+		t.Fatalf("expected unblocked to be claimed, got %+v", issue)
+	}
+
+	// Claiming a blocked issue by id explains why
+	_, err = repo.ClaimIssueByID(context.Background(), agent, "child", filters, domain.ClaimOptions{})
+	claimErr, ok := err.(*domain.ClaimFailed)
+	if !ok || claimErr.ErrorCode != domain.ErrCodeNotReady {
+		t.Errorf("expected NOT_READY error, got %v", err)
+	}
+
+	// Closing the blocker frees the whole subtree
+	execTestSQL(t, dbPath, `UPDATE issues SET status = 'closed' WHERE id = 'blocker'`)
+	ids = readyIDs(t, repo, filters)
+	if len(ids) != 2 || ids[0] != "epic" || ids[1] != "child" {
+		t.Errorf("expected [epic child], got %v", ids)
+	}
+}
+
+func TestReadiness_AutoWithoutCache(t *testing.T) {
+	dbPath, cleanup := setupDependencyFixture(t)
+	defer cleanup()
+	execTestSQL(t, dbPath, `DROP TABLE blocked_issues_cache`)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ids := readyIDs(t, repo, domain.NewClaimFilters())
+	if len(ids) != 2 || ids[0] != "unblocked" {
+		t.Errorf("expected auto to fall back to dependencies, got %v", ids)
+	}
+}
+
+func TestReadiness_NoDependencyTables(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	execTestSQL(t, dbPath, `DROP TABLE blocked_issues_cache`)
+
+	insertTestIssue(t, dbPath, "issue-1", "Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ids := readyIDs(t, repo, domain.NewClaimFilters())
+	if len(ids) != 1 {
+		t.Errorf("expected issue-1 to be ready when nothing can block it, got %v", ids)
+	}
+}
+
+func TestReadySelect_PreferLabels(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "plain", "Plain", "open", 1, nil)
+	insertTestIssue(t, dbPath, "fe", "Frontend", "open", 1, nil)
+	insertTestLabel(t, dbPath, "fe", "frontend")
+	insertTestIssue(t, dbPath, "fe-docs", "Frontend docs", "open", 1, nil)
+	insertTestLabel(t, dbPath, "fe-docs", "frontend")
+	insertTestLabel(t, dbPath, "fe-docs", "docs")
+	insertTestIssue(t, dbPath, "urgent", "Urgent", "open", 0, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.PreferLabels = []string{"frontend", "docs"}
+
+	issues, err := repo.FindReadyIssues(context.Background(), filters, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Preference never outranks priority
+	want := []domain.IssueId{"urgent", "fe-docs", "fe", "plain"}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d", len(want), len(issues))
+	}
+	for i, issue := range issues {
+		if issue.ID != want[i] {
+			t.Errorf("position %d: expected %s, got %s", i, want[i], issue.ID)
+		}
+	}
+}
+
+func TestReadySelect_Parent(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	addDependenciesTable(t, dbPath)
+
+	//	epic <- story <- task
+	//	     <- bug
+	//	other <- stray
+	insertTestIssue(t, dbPath, "epic", "Epic", "open", 0, nil)
+	insertTestIssue(t, dbPath, "story", "Story", "open", 1, nil)
+	insertTestIssue(t, dbPath, "task", "Task", "open", 2, nil)
+	insertTestIssue(t, dbPath, "bug", "Bug", "open", 3, nil)
+	insertTestIssue(t, dbPath, "other", "Other", "open", 0, nil)
+	insertTestIssue(t, dbPath, "stray", "Stray", "open", 0, nil)
+	insertTestDependency(t, dbPath, "story", "epic", "parent-child")
+	insertTestDependency(t, dbPath, "task", "story", "parent-child")
+	insertTestDependency(t, dbPath, "bug", "epic", "parent-child")
+	insertTestDependency(t, dbPath, "stray", "other", "parent-child")
+	// A blocks edge does not make a child
+	insertTestDependency(t, dbPath, "other", "epic", "blocks")
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.Parent = "epic"
+	if got := fmt.Sprint(readyIDs(t, repo, filters)); got != "[story task bug]" {
+		t.Errorf("expected the whole subtree, got %s", got)
+	}
+
+	filters.DirectChildren = true
+	if got := fmt.Sprint(readyIDs(t, repo, filters)); got != "[story bug]" {
+		t.Errorf("expected only direct children, got %s", got)
+	}
+
+	_, err = repo.ClaimIssueByID(context.Background(), "agent-a", "task", filters, domain.ClaimOptions{})
+	claimErr, ok := err.(*domain.ClaimFailed)
+	if !ok || claimErr.ErrorCode != domain.ErrCodeNotReady || !strings.Contains(claimErr.Message, "not a child of epic") {
+		t.Errorf("expected NOT_READY for a grandchild, got %v", err)
+	}
+
+	filters = domain.NewClaimFilters()
+	filters.Parent = "missing"
+	_, err = repo.FindReadyIssues(context.Background(), filters, 10)
+	if claimErr, ok := err.(*domain.ClaimFailed); !ok || claimErr.ErrorCode != domain.ErrCodeNotFound {
+		t.Errorf("expected NOT_FOUND for an unknown parent, got %v", err)
+	}
+}
+
+func TestReadySelect_ParentWithoutDependencies(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertTestIssue(t, dbPath, "epic", "Epic", "open", 0, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.Parent = "epic"
+	_, err = repo.FindReadyIssues(context.Background(), filters, 10)
+	if claimErr, ok := err.(*domain.ClaimFailed); !ok || claimErr.ErrorCode != domain.ErrCodeSchemaIncompatible {
+		t.Errorf("expected SCHEMA_INCOMPATIBLE, got %v", err)
+	}
+}
@@ -30,7 +30,9 @@ func NewSQLiteIssueRepository(dbPath string, busyTimeout int) (*SQLiteIssueRepos
 		busyTimeout = defaultBusyTimeout
 	}
 
-	dsn := fmt.Sprintf("%s?_busy_timeout=%d&_journal_mode=WAL", dbPath, busyTimeout)
+	// Write transactions begin IMMEDIATE so a claim holds the write lock from
+	// candidate selection through commit.
+	dsn := fmt.Sprintf("%s?_busy_timeout=%d&_journal_mode=WAL&_txlock=immediate", dbPath, busyTimeout)
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, &domain.ClaimFailed{
@@ -66,14 +68,44 @@ func (r *SQLiteIssueRepository) ClaimOneReadyIssue(
 	ctx context.Context,
 	agent domain.AgentName,
 	filters domain.ClaimFilters,
+	opts domain.ClaimOptions,
 ) (*domain.Issue, error) {
-	var issue *domain.Issue
+	issues, err := r.ClaimReadyIssues(ctx, agent, filters, 1, opts)
+	if err != nil || len(issues) == 0 {
+		return nil, err
+	}
+	return issues[0], nil
+}
+
+// ClaimReadyIssues atomically claims up to n ready issues in one transaction,
+// in the same order ClaimOneReadyIssue would pick them.
+func (r *SQLiteIssueRepository) ClaimReadyIssues(
+	ctx context.Context,
+	agent domain.AgentName,
+	filters domain.ClaimFilters,
+	n int,
+	opts domain.ClaimOptions,
+) ([]*domain.Issue, error) {
+	var issues []*domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issues, err = r.tryClaimIssues(ctx, agent, filters, n, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// withRetry runs op, retrying with exponential backoff while SQLite reports busy.
+func (r *SQLiteIssueRepository) withRetry(op func() error) error {
 	var err error
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		issue, err = r.tryClaimIssue(ctx, agent, filters)
+		err = op()
 		if err == nil {
-			return issue, nil
+			return nil
 		}
 
 		// Check if error is retryable (SQLITE_BUSY)
@@ -86,13 +118,709 @@ func (r *SQLiteIssueRepository) ClaimOneReadyIssue(
 		break
 	}
 
-	return nil, err
+	return err
+}
+
+// claimCandidate is a ready issue selected for claiming, with the state it
+// had before the claim.
+type claimCandidate struct {
+	id       domain.IssueId
+	status   string
+	assignee sql.NullString
+	score    sql.NullInt64
+}
+
+func (r *SQLiteIssueRepository) tryClaimIssues(
+	ctx context.Context,
+	agent domain.AgentName,
+	filters domain.ClaimFilters,
+	n int,
+	opts domain.ClaimOptions,
+) ([]*domain.Issue, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to begin transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	n, err = r.wipCapacity(ctx, tx, agent, opts.MaxInProgress, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// A batch that admits issues itself selects every ready one
+	admission, err := newBatchAdmission(ctx, tx, filters, n)
+	if err != nil {
+		return nil, err
+	}
+	selectN := n
+	if admission != nil {
+		selectN = -1 // no LIMIT
+	}
+
+	candidates, err := r.selectClaimCandidates(ctx, tx, filters, "", selectN, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*domain.Issue
+	for _, c := range candidates {
+		if len(issues) == n {
+			break
+		}
+		if admission != nil {
+			labels, err := r.fetchLabels(ctx, tx, c.id)
+			if err != nil {
+				return nil, err
+			}
+			if !admission.admit(labels, c.status) {
+				continue
+			}
+		}
+
+		issue, err := r.claimCandidate(ctx, tx, agent, c, opts, now)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, issue)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to commit transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return issues, nil
+}
+
+// wipCapacity returns how many of n issues the agent may claim without
+// exceeding limit issues in progress. An agent already at its limit fails
+// with WIP_LIMIT_REACHED, listing the issues it holds. A zero limit allows n.
+func (r *SQLiteIssueRepository) wipCapacity(
+	ctx context.Context,
+	tx *sql.Tx,
+	agent domain.AgentName,
+	limit int,
+	n int,
+) (int, error) {
+	if limit <= 0 {
+		return n, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT i.id FROM issues i
+		WHERE i.status = 'in_progress' AND i.assignee = ?
+		ORDER BY i.priority ASC, i.created_at ASC, i.id ASC
+	`, agent.String())
+	if err != nil {
+		return 0, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to count issues in progress: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	var ids []domain.IssueId
+	for rows.Next() {
+		var id domain.IssueId
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to count issues in progress: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to count issues in progress: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	if remaining := limit - len(ids); remaining > 0 {
+		return min(n, remaining), nil
+	}
+
+	issues := make([]*domain.Issue, 0, len(ids))
+	for _, id := range ids {
+		issue, err := r.fetchIssue(ctx, tx, id)
+		if err != nil {
+			return 0, err
+		}
+		issues = append(issues, issue)
+	}
+	return 0, &domain.ClaimFailed{
+		Agent:      &agent,
+		ErrorCode:  domain.ErrCodeWIPLimitReached,
+		Message:    fmt.Sprintf("agent %s already has %d issues in progress (limit %d)", agent, len(ids), limit),
+		OccurredAt: domain.Now(),
+		Issues:     issues,
+	}
+}
+
+// selectClaimCandidates picks up to n ready issues while holding the write
+// lock (transactions begin IMMEDIATE), so the previous assignee can be
+// recorded in the audit trail. A non-empty onlyID restricts the selection to
+// that issue.
+func (r *SQLiteIssueRepository) selectClaimCandidates(
+	ctx context.Context,
+	tx *sql.Tx,
+	filters domain.ClaimFilters,
+	onlyID domain.IssueId,
+	n int,
+	now time.Time,
+) ([]claimCandidate, error) {
+	selectQuery, args, err := r.readySelect(ctx, tx, "i.id, i.status, i.assignee", filters, onlyID, now)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, n)
+
+	rows, err := tx.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		if isBusyError(err) {
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeSQLiteBusy,
+				Message:    "database is busy: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to select issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	defer rows.Close()
+
+	var candidates []claimCandidate
+	for rows.Next() {
+		var c claimCandidate
+		if err := rows.Scan(&c.id, &c.status, &c.assignee, &c.score); err != nil {
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to scan issue: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to select issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return candidates, nil
+}
+
+// ClaimIssueByID atomically claims the named issue, provided it is ready and
+// matches filters. Unlike ClaimOneReadyIssue it never returns nil: an issue
+// that cannot be claimed fails with NOT_FOUND, ALREADY_CLAIMED or NOT_READY.
+func (r *SQLiteIssueRepository) ClaimIssueByID(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	filters domain.ClaimFilters,
+	opts domain.ClaimOptions,
+) (*domain.Issue, error) {
+	var issue *domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issue, err = r.tryClaimIssueByID(ctx, agent, issueID, filters, opts, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// FindIssueByID checks that the named issue could be claimed, without
+// claiming it. It fails with the same error codes as ClaimIssueByID.
+func (r *SQLiteIssueRepository) FindIssueByID(
+	ctx context.Context,
+	issueID domain.IssueId,
+	filters domain.ClaimFilters,
+) (*domain.Issue, error) {
+	var issue *domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issue, err = r.tryClaimIssueByID(ctx, "", issueID, filters, domain.ClaimOptions{}, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+func (r *SQLiteIssueRepository) tryClaimIssueByID(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	filters domain.ClaimFilters,
+	opts domain.ClaimOptions,
+	dryRun bool,
+) (*domain.Issue, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to begin transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	candidates, err := r.selectClaimCandidates(ctx, tx, filters, issueID, 1, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, r.explainNotClaimable(ctx, tx, agent, issueID, filters)
+	}
+
+	if dryRun {
+		issue, err := r.fetchIssue(ctx, tx, issueID)
+		if err != nil {
+			return nil, err
+		}
+		issue.Unblocks = unblocks(candidates[0].score)
+		return issue, nil
+	}
+
+	if _, err := r.wipCapacity(ctx, tx, agent, opts.MaxInProgress, 1); err != nil {
+		return nil, err
+	}
+
+	issue, err := r.claimCandidate(ctx, tx, agent, candidates[0], opts, now)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return nil, r.explainNotClaimable(ctx, tx, agent, issueID, filters)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to commit transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return issue, nil
+}
+
+// explainNotClaimable builds the error for a named issue that the ready
+// selection rejected.
+func (r *SQLiteIssueRepository) explainNotClaimable(
+	ctx context.Context,
+	tx *sql.Tx,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	filters domain.ClaimFilters,
+) error {
+	fail := func(code domain.ClaimErrorCode, message string) error {
+		claimErr := &domain.ClaimFailed{
+			ErrorCode:  code,
+			Message:    message,
+			OccurredAt: domain.Now(),
+		}
+		if agent != "" {
+			claimErr.Agent = &agent
+		}
+		return claimErr
+	}
+
+	issue, err := r.fetchIssue(ctx, tx, issueID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fail(domain.ErrCodeNotFound, "issue "+issueID.String()+" not found")
+	}
+
+	if issue.Status == domain.StatusInProgress {
+		holder := "another agent"
+		if issue.Assignee != nil {
+			holder = issue.Assignee.String()
+		}
+		return fail(domain.ErrCodeAlreadyClaimed, fmt.Sprintf("issue %s is already claimed by %s", issueID, holder))
+	}
+	if issue.Status != domain.StatusOpen {
+		return fail(domain.ErrCodeNotReady, fmt.Sprintf("issue %s is %s, not open", issueID, issue.Status))
+	}
+
+	blocked, err := isBlocked(ctx, tx, filters, issueID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return fail(domain.ErrCodeNotReady, fmt.Sprintf("issue %s is blocked by open dependencies", issueID))
+	}
+
+	within, err := inSubtree(ctx, tx, filters, issueID)
+	if err != nil {
+		return err
+	}
+	if !within {
+		relation := "a descendant"
+		if filters.DirectChildren {
+			relation = "a child"
+		}
+		return fail(domain.ErrCodeNotReady, fmt.Sprintf("issue %s is not %s of %s", issueID, relation, filters.Parent))
+	}
+
+	label, err := labelAtLimit(ctx, tx, filters.LabelLimits, issue)
+	if err != nil {
+		return err
+	}
+	if label != "" {
+		return fail(domain.ErrCodeNotReady, fmt.Sprintf("label %s is at its limit of %d issues in progress", label, filters.LabelLimits[label]))
+	}
+
+	label, holder, err := exclusiveConflict(ctx, tx, filters.ExclusivePrefixes, issue)
+	if err != nil {
+		return err
+	}
+	if label != "" {
+		return fail(domain.ErrCodeNotReady, fmt.Sprintf("issue %s shares %s with %s, which is in progress", issueID, label, holder))
+	}
+
+	return fail(domain.ErrCodeNotReady, fmt.Sprintf("issue %s does not match the claim filters", issueID))
+}
+
+// claimCandidate claims one selected issue inside tx. It returns nil if the
+// issue changed since it was selected.
+func (r *SQLiteIssueRepository) claimCandidate(
+	ctx context.Context,
+	tx *sql.Tx,
+	agent domain.AgentName,
+	c claimCandidate,
+	opts domain.ClaimOptions,
+	now time.Time,
+) (*domain.Issue, error) {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE issues
+		SET status = 'in_progress',
+			assignee = ?,
+			updated_at = ?
+		WHERE id = ?
+		AND status = ?
+	`, agent.String(), now.Format(time.RFC3339Nano), c.id.String(), c.status)
+	if err != nil {
+		if isBusyError(err) {
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeSQLiteBusy,
+				Message:    "database is busy: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to update issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to get rows affected: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	if rowsAffected == 0 {
+		// No issue claimed - lost race
+		return nil, nil
+	}
+
+	// Record the claim in the Beads audit trail atomically with the update
+	reclaimed := domain.IssueStatus(c.status) == domain.StatusInProgress
+	events := claimEvents(c.id, agent, c.assignee)
+	if reclaimed {
+		events = reclaimEvents(c.id, agent, domain.AgentName(c.assignee.String))
+	}
+	if err := recordEvents(ctx, tx, events, now); err != nil {
+		return nil, err
+	}
+
+	// Flag the change for the Beads JSONL export
+	if err := markDirty(ctx, tx, c.id, now); err != nil {
+		return nil, err
+	}
+
+	leaseExpiresAt, err := saveLease(ctx, tx, c.id, agent, opts.Lease, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := deleteHeartbeat(ctx, tx, c.id); err != nil {
+		return nil, err
+	}
+
+	// Fetch the claimed issue
+	issue, err := r.fetchIssue(ctx, tx, c.id)
+	if err != nil {
+		return nil, err
+	}
+	issue.LeaseExpiresAt = leaseExpiresAt
+	issue.Unblocks = unblocks(c.score)
+	if reclaimed {
+		previous := domain.AgentName(c.assignee.String)
+		issue.ReclaimedFrom = &previous
+	}
+
+	return issue, nil
+}
+
+// ReleaseIssue atomically returns an issue to the ready pool, provided it is
+// still in progress and assigned to the agent.
+func (r *SQLiteIssueRepository) ReleaseIssue(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	reason string,
+) (*domain.Issue, error) {
+	var issue *domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issue, err = r.tryReleaseIssue(ctx, agent, issueID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+func (r *SQLiteIssueRepository) tryReleaseIssue(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	reason string,
+) (*domain.Issue, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to begin transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	defer tx.Rollback()
+
+	issue, err := r.fetchOwnedIssue(ctx, tx, agent, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE issues
+		SET status = 'open',
+			assignee = NULL,
+			updated_at = ?
+		WHERE id = ?
+		AND status = 'in_progress'
+		AND assignee = ?
+	`, now.Format(time.RFC3339Nano), issueID.String(), agent.String())
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to release issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	issue.Release(agent, reason, now)
+
+	if err := deleteLease(ctx, tx, issueID); err != nil {
+		return nil, err
+	}
+	if err := deleteHeartbeat(ctx, tx, issueID); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvents(ctx, tx, releaseEvents(issueID, agent, reason), now); err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		if err := addComment(ctx, tx, issueID, agent, "Released by "+agent.String()+": "+reason, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := markDirty(ctx, tx, issueID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to commit transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return issue, nil
+}
+
+// CloseIssue atomically closes an issue, provided it is still in progress and
+// assigned to the agent. closed_at and close_reason are set when the schema
+// has them.
+func (r *SQLiteIssueRepository) CloseIssue(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	reason string,
+) (*domain.Issue, error) {
+	var issue *domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issue, err = r.tryCloseIssue(ctx, agent, issueID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+func (r *SQLiteIssueRepository) tryCloseIssue(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+	reason string,
+) (*domain.Issue, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to begin transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	defer tx.Rollback()
+
+	issue, err := r.fetchHeldIssue(ctx, tx, agent, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	set := "status = 'closed', updated_at = ?"
+	args := []interface{}{now.Format(time.RFC3339Nano)}
+
+	// Beads requires closed_at on closed issues where the column exists
+	hasClosedAt, err := hasColumn(ctx, tx, "issues", "closed_at")
+	if err != nil {
+		return nil, err
+	}
+	if hasClosedAt {
+		set += ", closed_at = ?"
+		args = append(args, now.Format(time.RFC3339Nano))
+	}
+	hasCloseReason, err := hasColumn(ctx, tx, "issues", "close_reason")
+	if err != nil {
+		return nil, err
+	}
+	if hasCloseReason {
+		set += ", close_reason = ?"
+		args = append(args, reason)
+	}
+
+	args = append(args, issueID.String(), agent.String())
+	_, err = tx.ExecContext(ctx, `
+		UPDATE issues
+		SET `+set+`
+		WHERE id = ?
+		AND status = 'in_progress'
+		AND assignee = ?
+	`, args...)
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to close issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	issue.Close(agent, reason, now)
+
+	if err := deleteLease(ctx, tx, issueID); err != nil {
+		return nil, err
+	}
+	if err := deleteHeartbeat(ctx, tx, issueID); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvents(ctx, tx, closeEvents(issueID, agent, reason), now); err != nil {
+		return nil, err
+	}
+	if err := markDirty(ctx, tx, issueID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to commit transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	return issue, nil
+}
+
+// TransferIssue atomically reassigns a claimed issue from one agent to
+// another. The issue stays in progress, and a lease held by the old owner
+// carries over to the new one for its original duration.
+func (r *SQLiteIssueRepository) TransferIssue(
+	ctx context.Context,
+	from domain.AgentName,
+	to domain.AgentName,
+	issueID domain.IssueId,
+	reason string,
+) (*domain.Issue, error) {
+	var issue *domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issue, err = r.tryTransferIssue(ctx, from, to, issueID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issue, nil
 }
 
-func (r *SQLiteIssueRepository) tryClaimIssue(
+func (r *SQLiteIssueRepository) tryTransferIssue(
 	ctx context.Context,
-	agent domain.AgentName,
-	filters domain.ClaimFilters,
+	from domain.AgentName,
+	to domain.AgentName,
+	issueID domain.IssueId,
+	reason string,
 ) (*domain.Issue, error) {
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
 	if err != nil {
@@ -104,64 +832,117 @@ func (r *SQLiteIssueRepository) tryClaimIssue(
 	}
 	defer tx.Rollback()
 
-	// Build the WHERE clause based on filters
-	whereClause, args := r.buildWhereClause(filters)
+	issue, err := r.fetchHeldIssue(ctx, tx, from, issueID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Find and update in one atomic operation using a subquery
 	now := time.Now()
-	query := fmt.Sprintf(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE issues
-		SET status = 'in_progress',
-			assignee = ?,
+		SET assignee = ?,
 			updated_at = ?
-		WHERE id = (
-			SELECT i.id
-			FROM issues i
-			LEFT JOIN blocked_issues_cache b ON i.id = b.issue_id
-			WHERE i.status = 'open'
-			AND b.issue_id IS NULL
-			%s
-			ORDER BY i.priority DESC, i.created_at ASC, i.id ASC
-			LIMIT 1
-		)
-		AND status = 'open'
-	`, whereClause)
-
-	// Prepend agent and timestamp to args
-	allArgs := append([]interface{}{agent.String(), now.Format(time.RFC3339Nano)}, args...)
-
-	result, err := tx.ExecContext(ctx, query, allArgs...)
+		WHERE id = ?
+		AND status = 'in_progress'
+		AND assignee = ?
+	`, to.String(), now.Format(time.RFC3339Nano), issueID.String(), from.String())
 	if err != nil {
-		if isBusyError(err) {
-			return nil, &domain.ClaimFailed{
-				ErrorCode:  domain.ErrCodeSQLiteBusy,
-				Message:    "database is busy: " + err.Error(),
-				OccurredAt: domain.Now(),
-			}
-		}
 		return nil, &domain.ClaimFailed{
 			ErrorCode:  domain.ErrCodeUnexpected,
-			Message:    "failed to update issue: " + err.Error(),
+			Message:    "failed to transfer issue: " + err.Error(),
 			OccurredAt: domain.Now(),
 		}
 	}
+	issue.Transfer(from, to, reason, now)
 
-	rowsAffected, err := result.RowsAffected()
+	leaseExpiresAt, err := transferLease(ctx, tx, issueID, from, to, now)
 	if err != nil {
+		return nil, err
+	}
+	issue.LeaseExpiresAt = leaseExpiresAt
+	if err := deleteHeartbeat(ctx, tx, issueID); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvents(ctx, tx, transferEvents(issueID, from, to, reason), now); err != nil {
+		return nil, err
+	}
+	if err := markDirty(ctx, tx, issueID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, &domain.ClaimFailed{
-			ErrorCode:  domain.ErrCodeUnexpected,
-			Message:    "failed to get rows affected: " + err.Error(),
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to commit transaction: " + err.Error(),
 			OccurredAt: domain.Now(),
 		}
 	}
 
-	if rowsAffected == 0 {
-		// No issue claimed - either none available or lost race
-		return nil, nil
+	return issue, nil
+}
+
+// Heartbeat records that the agent is still working on its claimed issue and
+// renews the claim's lease, if it has one. It fails with LEASE_LOST once the
+// agent no longer owns the issue.
+func (r *SQLiteIssueRepository) Heartbeat(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+) (*domain.Issue, error) {
+	var issue *domain.Issue
+	err := r.withRetry(func() error {
+		var err error
+		issue, err = r.tryHeartbeat(ctx, agent, issueID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return issue, nil
+}
 
-	// Fetch the claimed issue
-	issue, err := r.fetchClaimedIssue(ctx, tx, agent)
+func (r *SQLiteIssueRepository) tryHeartbeat(
+	ctx context.Context,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+) (*domain.Issue, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeSQLiteBusy,
+			Message:    "failed to begin transaction: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+	defer tx.Rollback()
+
+	issue, err := r.fetchIssue(ctx, tx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return nil, &domain.ClaimFailed{
+			Agent:      &agent,
+			ErrorCode:  domain.ErrCodeNotFound,
+			Message:    "issue " + issueID.String() + " not found",
+			OccurredAt: domain.Now(),
+		}
+	}
+	if !issue.IsOwnedBy(agent) {
+		return nil, &domain.ClaimFailed{
+			Agent:      &agent,
+			ErrorCode:  domain.ErrCodeLeaseLost,
+			Message:    fmt.Sprintf("issue %s is no longer claimed by %s; stop work", issueID, agent),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	now := time.Now()
+	if err := saveHeartbeat(ctx, tx, issueID, agent, now); err != nil {
+		return nil, err
+	}
+	leaseExpiresAt, err := renewLease(ctx, tx, issueID, agent, now)
 	if err != nil {
 		return nil, err
 	}
@@ -174,29 +955,126 @@ func (r *SQLiteIssueRepository) tryClaimIssue(
 		}
 	}
 
+	issue.LastHeartbeatAt = &now
+	issue.LeaseExpiresAt = leaseExpiresAt
+	return issue, nil
+}
+
+// fetchOwnedIssue loads an issue inside tx and verifies the agent currently owns it.
+func (r *SQLiteIssueRepository) fetchOwnedIssue(
+	ctx context.Context,
+	tx *sql.Tx,
+	agent domain.AgentName,
+	issueID domain.IssueId,
+) (*domain.Issue, error) {
+	issue, err := r.fetchIssue(ctx, tx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return nil, &domain.ClaimFailed{
+			Agent:      &agent,
+			ErrorCode:  domain.ErrCodeNotFound,
+			Message:    "issue " + issueID.String() + " not found",
+			OccurredAt: domain.Now(),
+		}
+	}
+	if !issue.IsOwnedBy(agent) {
+		return nil, &domain.ClaimFailed{
+			Agent:      &agent,
+			ErrorCode:  domain.ErrCodeNotOwner,
+			Message:    fmt.Sprintf("issue %s is not in progress and assigned to %s", issueID, agent),
+			OccurredAt: domain.Now(),
+		}
+	}
 	return issue, nil
 }
 
-func (r *SQLiteIssueRepository) fetchClaimedIssue(
+// fetchHeldIssue loads an issue inside tx and verifies the agent holds it,
+// explaining precisely why not otherwise.
+func (r *SQLiteIssueRepository) fetchHeldIssue(
 	ctx context.Context,
 	tx *sql.Tx,
 	agent domain.AgentName,
+	issueID domain.IssueId,
+) (*domain.Issue, error) {
+	issue, err := r.fetchIssue(ctx, tx, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	fail := func(code domain.ClaimErrorCode, message string) (*domain.Issue, error) {
+		return nil, &domain.ClaimFailed{
+			Agent:      &agent,
+			ErrorCode:  code,
+			Message:    message,
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	switch {
+	case issue == nil:
+		return fail(domain.ErrCodeNotFound, "issue "+issueID.String()+" not found")
+	case issue.Status == domain.StatusClosed:
+		return fail(domain.ErrCodeAlreadyClosed, "issue "+issueID.String()+" is already closed")
+	case issue.Status != domain.StatusInProgress:
+		return fail(domain.ErrCodeNotClaimed, fmt.Sprintf("issue %s is %s, not in progress", issueID, issue.Status))
+	case issue.Assignee == nil:
+		return fail(domain.ErrCodeNotOwner, fmt.Sprintf("issue %s is in progress but unassigned", issueID))
+	case *issue.Assignee != agent:
+		return fail(domain.ErrCodeNotOwner, fmt.Sprintf("issue %s is assigned to %s, not %s", issueID, *issue.Assignee, agent))
+	}
+	return issue, nil
+}
+
+func (r *SQLiteIssueRepository) fetchIssue(
+	ctx context.Context,
+	tx *sql.Tx,
+	issueID domain.IssueId,
 ) (*domain.Issue, error) {
 	query := `
 		SELECT i.id, i.title, i.description, i.status, i.assignee, i.priority,
 			   i.issue_type, i.created_at, i.updated_at
 		FROM issues i
-		WHERE i.assignee = ? AND i.status = 'in_progress'
-		ORDER BY i.updated_at DESC
-		LIMIT 1
+		WHERE i.id = ?
 	`
 
+	issue, err := scanIssue(tx.QueryRowContext(ctx, query, issueID.String()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to fetch issue: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
+	}
+
+	// Fetch labels
+	labels, err := r.fetchLabels(ctx, tx, issue.ID)
+	if err != nil {
+		return nil, err
+	}
+	issue.Labels = labels
+
+	return issue, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanIssue reads the issue columns selected by fetchIssue and
+// FindReadyIssues, then any extra columns into extra.
+func scanIssue(row rowScanner, extra ...interface{}) (*domain.Issue, error) {
 	var issue domain.Issue
 	var title, description, status, assignee, issueType sql.NullString
 	var priority sql.NullInt64
 	var createdAt, updatedAt string
 
-	err := tx.QueryRowContext(ctx, query, agent.String()).Scan(
+	dest := []interface{}{
 		&issue.ID,
 		&title,
 		&description,
@@ -206,16 +1084,10 @@ func (r *SQLiteIssueRepository) fetchClaimedIssue(
 		&issueType,
 		&createdAt,
 		&updatedAt,
-	)
+	}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, &domain.ClaimFailed{
-			ErrorCode:  domain.ErrCodeUnexpected,
-			Message:    "failed to fetch claimed issue: " + err.Error(),
-			OccurredAt: domain.Now(),
-		}
+		return nil, err
 	}
 
 	issue.Title = title.String
@@ -240,13 +1112,6 @@ func (r *SQLiteIssueRepository) fetchClaimedIssue(
 		issue.UpdatedAt = t
 	}
 
-	// Fetch labels
-	labels, err := r.fetchLabels(ctx, tx, issue.ID)
-	if err != nil {
-		return nil, err
-	}
-	issue.Labels = labels
-
 	return &issue, nil
 }
 
@@ -287,77 +1152,122 @@ func (r *SQLiteIssueRepository) FindOneReadyIssue(
 	ctx context.Context,
 	filters domain.ClaimFilters,
 ) (*domain.Issue, error) {
-	whereClause, args := r.buildWhereClause(filters)
+	issues, err := r.FindReadyIssues(ctx, filters, 1)
+	if err != nil || len(issues) == 0 {
+		return nil, err
+	}
+	return issues[0], nil
+}
+
+// FindReadyIssues lists up to n ready issues, in claim order, without claiming
+// them. They are the issues ClaimReadyIssues would claim as a batch of n.
+func (r *SQLiteIssueRepository) FindReadyIssues(
+	ctx context.Context,
+	filters domain.ClaimFilters,
+	n int,
+) ([]*domain.Issue, error) {
+	admission, err := newBatchAdmission(ctx, r.db, filters, n)
+	if err != nil {
+		return nil, err
+	}
+	limit := n
+	if admission != nil {
+		limit = -1 // no LIMIT
+	}
+
+	query, args, err := r.readySelect(ctx, r.db, `i.id, i.title, i.description, i.status, i.assignee, i.priority,
+			   i.issue_type, i.created_at, i.updated_at`, filters, "", time.Now())
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, limit)
+
+	ready, err := r.queryIssues(ctx, query, true, args...)
+	if err != nil || admission == nil {
+		return ready, err
+	}
+
+	var issues []*domain.Issue
+	for _, issue := range ready {
+		if len(issues) == n {
+			break
+		}
+		if admission.admit(issue.Labels, string(issue.Status)) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
 
-	query := fmt.Sprintf(`
+// FindIssuesByAssignee lists the issues the agent has in progress, most
+// urgent first.
+func (r *SQLiteIssueRepository) FindIssuesByAssignee(
+	ctx context.Context,
+	agent domain.AgentName,
+) ([]*domain.Issue, error) {
+	query := `
 		SELECT i.id, i.title, i.description, i.status, i.assignee, i.priority,
 			   i.issue_type, i.created_at, i.updated_at
 		FROM issues i
-		LEFT JOIN blocked_issues_cache b ON i.id = b.issue_id
-		WHERE i.status = 'open'
-		AND b.issue_id IS NULL
-		%s
-		ORDER BY i.priority DESC, i.created_at ASC, i.id ASC
-		LIMIT 1
-	`, whereClause)
+		WHERE i.status = 'in_progress' AND i.assignee = ?
+		ORDER BY i.priority ASC, i.created_at ASC, i.id ASC
+	`
 
-	var issue domain.Issue
-	var title, description, status, assignee, issueType sql.NullString
-	var priority sql.NullInt64
-	var createdAt, updatedAt string
+	return r.queryIssues(ctx, query, false, agent.String())
+}
 
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
-		&issue.ID,
-		&title,
-		&description,
-		&status,
-		&assignee,
-		&priority,
-		&issueType,
-		&createdAt,
-		&updatedAt,
-	)
+// queryIssues runs a query selecting the issue columns scanIssue reads and
+// loads each issue's labels. A scored query selects readySelect's score
+// column after them.
+func (r *SQLiteIssueRepository) queryIssues(ctx context.Context, query string, scored bool, args ...interface{}) ([]*domain.Issue, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
 		return nil, &domain.ClaimFailed{
 			ErrorCode:  domain.ErrCodeUnexpected,
-			Message:    "failed to query ready issue: " + err.Error(),
+			Message:    "failed to query issues: " + err.Error(),
 			OccurredAt: domain.Now(),
 		}
 	}
 
-	issue.Title = title.String
-	issue.Description = description.String
-	issue.Status = domain.IssueStatus(status.String)
-	if assignee.Valid {
-		a := domain.AgentName(assignee.String)
-		issue.Assignee = &a
-	}
-	issue.Priority = domain.Priority(priority.Int64)
-	issue.IssueType = issueType.String
-
-	// Parse timestamps
-	if t, err := time.Parse(time.RFC3339Nano, createdAt); err == nil {
-		issue.CreatedAt = t
-	} else if t, err := time.Parse("2006-01-02T15:04:05.999999-07:00", createdAt); err == nil {
-		issue.CreatedAt = t
+	var issues []*domain.Issue
+	for rows.Next() {
+		var score sql.NullInt64
+		var extra []interface{}
+		if scored {
+			extra = append(extra, &score)
+		}
+		issue, err := scanIssue(rows, extra...)
+		if err != nil {
+			rows.Close()
+			return nil, &domain.ClaimFailed{
+				ErrorCode:  domain.ErrCodeUnexpected,
+				Message:    "failed to query issues: " + err.Error(),
+				OccurredAt: domain.Now(),
+			}
+		}
+		issue.Unblocks = unblocks(score)
+		issues = append(issues, issue)
 	}
-	if t, err := time.Parse(time.RFC3339Nano, updatedAt); err == nil {
-		issue.UpdatedAt = t
-	} else if t, err := time.Parse("2006-01-02T15:04:05.999999-07:00", updatedAt); err == nil {
-		issue.UpdatedAt = t
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, &domain.ClaimFailed{
+			ErrorCode:  domain.ErrCodeUnexpected,
+			Message:    "failed to query issues: " + err.Error(),
+			OccurredAt: domain.Now(),
+		}
 	}
 
 	// Fetch labels (need to use db directly, not tx)
-	labels, err := r.fetchLabelsFromDb(ctx, issue.ID)
-	if err != nil {
-		return nil, err
+	for _, issue := range issues {
+		labels, err := r.fetchLabelsFromDb(ctx, issue.ID)
+		if err != nil {
+			return nil, err
+		}
+		issue.Labels = labels
 	}
-	issue.Labels = labels
 
-	return &issue, nil
+	return issues, nil
 }
 
 func (r *SQLiteIssueRepository) fetchLabelsFromDb(
@@ -399,10 +1309,15 @@ func (r *SQLiteIssueRepository) buildWhereClause(filters domain.ClaimFilters) (s
 		conditions = append(conditions, "AND i.assignee IS NULL")
 	}
 
+	// Priority range - P0 is the most urgent, so lower numbers sort first
 	if filters.MinPriority != nil {
 		conditions = append(conditions, "AND i.priority >= ?")
 		args = append(args, int(*filters.MinPriority))
 	}
+	if filters.MaxPriority != nil {
+		conditions = append(conditions, "AND i.priority <= ?")
+		args = append(args, int(*filters.MaxPriority))
+	}
 
 	// Include labels - issue must have ALL specified labels
 	for _, label := range filters.IncludeLabels {
@@ -416,9 +1331,72 @@ func (r *SQLiteIssueRepository) buildWhereClause(filters domain.ClaimFilters) (s
 		args = append(args, label)
 	}
 
+	// Issue types - an issue with no type is never excluded, but cannot be included
+	if len(filters.IncludeTypes) > 0 {
+		placeholders, typeArgs := inList(filters.IncludeTypes)
+		conditions = append(conditions, "AND i.issue_type IN ("+placeholders+")")
+		args = append(args, typeArgs...)
+	}
+	if len(filters.ExcludeTypes) > 0 {
+		placeholders, typeArgs := inList(filters.ExcludeTypes)
+		conditions = append(conditions, "AND (i.issue_type IS NULL OR i.issue_type NOT IN ("+placeholders+"))")
+		args = append(args, typeArgs...)
+	}
+
+	// Parent - only the issue's subtree, checked by readySelect to exist
+	if !filters.Parent.IsEmpty() {
+		subtree, subtreeArgs := subtreeQuery(filters.Parent, filters.DirectChildren)
+		conditions = append(conditions, "AND i.id IN ("+subtree+")")
+		args = append(args, subtreeArgs...)
+	}
+
+	// Label expression - any boolean combination of labels
+	if filters.Where != nil {
+		condition, exprArgs := labelExprCondition(filters.Where)
+		conditions = append(conditions, "AND "+condition)
+		args = append(args, exprArgs...)
+	}
+
+	// Label limits - skip issues whose labels are already fully staffed
+	if len(filters.LabelLimits) > 0 {
+		condition, limitArgs := labelLimitCondition(filters.LabelLimits)
+		conditions = append(conditions, condition)
+		args = append(args, limitArgs...)
+	}
+
+	// Exclusive groups - skip issues that would work on the same component
+	// as an issue already in progress
+	if len(filters.ExclusivePrefixes) > 0 {
+		condition, exclusiveArgs := exclusiveCondition(filters.ExclusivePrefixes)
+		conditions = append(conditions, condition)
+		args = append(args, exclusiveArgs...)
+	}
+
 	return strings.Join(conditions, " "), args
 }
 
+// labelExprCondition translates a label expression into SQL with the same
+// meaning as its Match method.
+func labelExprCondition(expr domain.LabelExpr) (string, []interface{}) {
+	switch e := expr.(type) {
+	case domain.LabelTerm:
+		return "EXISTS (SELECT 1 FROM labels l WHERE l.issue_id = i.id AND l.label = ?)", []interface{}{e.Label}
+	case domain.NotExpr:
+		x, args := labelExprCondition(e.X)
+		return "NOT " + x, args
+	case domain.AndExpr:
+		x, xArgs := labelExprCondition(e.X)
+		y, yArgs := labelExprCondition(e.Y)
+		return "(" + x + " AND " + y + ")", append(xArgs, yArgs...)
+	case domain.OrExpr:
+		x, xArgs := labelExprCondition(e.X)
+		y, yArgs := labelExprCondition(e.Y)
+		return "(" + x + " OR " + y + ")", append(xArgs, yArgs...)
+	}
+	// LabelExpr is sealed, so this is unreachable
+	panic(fmt.Sprintf("unknown label expression %T", expr))
+}
+
 func isBusyError(err error) bool {
 	if err == nil {
 		return false
@@ -168,7 +168,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.NewClaimFilters()
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -199,7 +199,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_NoIssues(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.NewClaimFilters()
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -224,7 +224,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_BlockedIssue(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.NewClaimFilters()
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -248,7 +248,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_InProgressIssue(t *testing.T)
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.NewClaimFilters()
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -274,7 +274,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithFilters(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.ClaimFilters{OnlyUnassigned: true}
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -304,7 +304,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithLabels(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.ClaimFilters{IncludeLabels: []string{"backend"}}
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -333,7 +333,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithExcludeLabels(t *testing.T
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.ClaimFilters{ExcludeLabels: []string{"wontfix"}}
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -345,12 +345,12 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithExcludeLabels(t *testing.T
 	}
 }
 
-func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithMinPriority(t *testing.T) {
+func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithMaxPriority(t *testing.T) {
 	dbPath, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	insertTestIssue(t, dbPath, "issue-1", "Low Priority", "open", 0, nil)
-	insertTestIssue(t, dbPath, "issue-2", "High Priority", "open", 2, nil)
+	insertTestIssue(t, dbPath, "issue-1", "Low Priority", "open", 3, nil)
+	insertTestIssue(t, dbPath, "issue-2", "High Priority", "open", 1, nil)
 
 	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
 	if err != nil {
@@ -359,10 +359,10 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_WithMinPriority(t *testing.T)
 	defer repo.Close()
 
 	agent, _ := domain.NewAgentName("test-agent")
-	minPriority := domain.PriorityHigh
-	filters := domain.ClaimFilters{MinPriority: &minPriority}
+	maxPriority := domain.PriorityHigh
+	filters := domain.ClaimFilters{MaxPriority: &maxPriority}
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -378,9 +378,9 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_PriorityOrder(t *testing.T) {
 	dbPath, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	insertTestIssue(t, dbPath, "issue-1", "Low Priority", "open", 0, nil)
-	insertTestIssue(t, dbPath, "issue-2", "High Priority", "open", 2, nil)
-	insertTestIssue(t, dbPath, "issue-3", "Medium Priority", "open", 1, nil)
+	insertTestIssue(t, dbPath, "issue-1", "Low Priority", "open", 3, nil)
+	insertTestIssue(t, dbPath, "issue-2", "High Priority", "open", 1, nil)
+	insertTestIssue(t, dbPath, "issue-3", "Medium Priority", "open", 2, nil)
 
 	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
 	if err != nil {
@@ -391,7 +391,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_PriorityOrder(t *testing.T) {
 	agent, _ := domain.NewAgentName("test-agent")
 	filters := domain.NewClaimFilters()
 
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -403,6 +403,37 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_PriorityOrder(t *testing.T) {
 	}
 }
 
+func TestSQLiteIssueRepository_ClaimOneReadyIssue_PriorityRange(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-p0", "Critical", "open", 0, nil)
+	insertTestIssue(t, dbPath, "issue-p2", "Medium", "open", 2, nil)
+	insertTestIssue(t, dbPath, "issue-p4", "Backlog", "open", 4, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	minPriority := domain.PriorityHigh
+	maxPriority := domain.PriorityLow
+	filters := domain.ClaimFilters{MinPriority: &minPriority, MaxPriority: &maxPriority}
+
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil {
+		t.Fatal("expected issue to be claimed")
+	}
+	if issue.ID != "issue-p2" {
+		t.Errorf("expected issue-p2 within P1-P3, got %s", issue.ID)
+	}
+}
+
 func TestSQLiteIssueRepository_FindOneReadyIssue(t *testing.T) {
 	dbPath, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -523,7 +554,7 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_Concurrency(t *testing.T) {
 	claimed := make(map[string]bool)
 	for i := 0; i < 5; i++ {
 		agent, _ := domain.NewAgentName(fmt.Sprintf("agent-%d", i))
-		issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters())
+		issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -541,6 +572,215 @@ func TestSQLiteIssueRepository_ClaimOneReadyIssue_Concurrency(t *testing.T) {
 	}
 }
 
+func TestSQLiteIssueRepository_ClaimReadyIssues(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-low", "Low", "open", 3, nil)
+	insertTestIssue(t, dbPath, "issue-high", "High", "open", 1, nil)
+	insertTestIssue(t, dbPath, "issue-medium", "Medium", "open", 2, nil)
+	insertTestIssue(t, dbPath, "issue-blocked", "Blocked", "open", 0, nil)
+	blockIssue(t, dbPath, "issue-blocked")
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+
+	// Dry-run lists the batch in claim order without claiming it
+	found, err := repo.FindReadyIssues(context.Background(), domain.NewClaimFilters(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != "issue-high" || found[1].ID != "issue-medium" {
+		t.Fatalf("unexpected ready list: %v", found)
+	}
+
+	issues, err := repo.ClaimReadyIssues(context.Background(), agent, domain.NewClaimFilters(), 2, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+	if issues[0].ID != "issue-high" || issues[1].ID != "issue-medium" {
+		t.Errorf("expected priority order, got %s, %s", issues[0].ID, issues[1].ID)
+	}
+	for _, issue := range issues {
+		if issue.Status != domain.StatusInProgress || issue.Assignee == nil || *issue.Assignee != agent {
+			t.Errorf("expected %s to be claimed by %s, got %+v", issue.ID, agent, issue)
+		}
+	}
+
+	// Asking for more than remain claims what is left
+	issues, err = repo.ClaimReadyIssues(context.Background(), agent, domain.NewClaimFilters(), 5, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != "issue-low" {
+		t.Errorf("expected only issue-low, got %v", issues)
+	}
+
+	issues, err = repo.ClaimReadyIssues(context.Background(), agent, domain.NewClaimFilters(), 5, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %d", len(issues))
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimReadyIssues_RaceNoOverlap(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	const numIssues = 12
+	for i := 0; i < numIssues; i++ {
+		insertTestIssue(t, dbPath, fmt.Sprintf("task-%02d", i), fmt.Sprintf("Task %d", i), "open", 1, nil)
+	}
+
+	const numAgents = 4
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claimedBy := make(map[string]string)
+	start := make(chan struct{})
+
+	for i := 0; i < numAgents; i++ {
+		wg.Add(1)
+		go func(agentNum int) {
+			defer wg.Done()
+
+			repo, err := NewSQLiteIssueRepository(dbPath, 5000)
+			if err != nil {
+				t.Errorf("failed to create repo: %v", err)
+				return
+			}
+			defer repo.Close()
+
+			agent, _ := domain.NewAgentName(fmt.Sprintf("planner-%d", agentNum))
+			<-start
+
+			issues, err := repo.ClaimReadyIssues(context.Background(), agent, domain.NewClaimFilters(), 3, domain.ClaimOptions{})
+			if err != nil {
+				t.Errorf("agent %s got error: %v", agent, err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, issue := range issues {
+				if previous, exists := claimedBy[issue.ID.String()]; exists {
+					t.Errorf("DOUBLE CLAIM! Issue %s claimed by both %s and %s", issue.ID, previous, agent)
+				}
+				claimedBy[issue.ID.String()] = agent.String()
+			}
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	if len(claimedBy) != numIssues {
+		t.Errorf("expected %d claimed issues, got %d", numIssues, len(claimedBy))
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimIssueByID(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "issue-1", "First", "open", 1, nil)
+	insertTestIssue(t, dbPath, "issue-2", "Second", "open", 3, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+
+	// Dry-run verifies without claiming
+	found, err := repo.FindIssueByID(context.Background(), "issue-2", domain.NewClaimFilters())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.ID != "issue-2" || found.Status != domain.StatusOpen {
+		t.Errorf("expected open issue-2, got %+v", found)
+	}
+
+	// The named issue is claimed even though issue-1 is more urgent
+	issue, err := repo.ClaimIssueByID(context.Background(), agent, "issue-2", domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.ID != "issue-2" || issue.Status != domain.StatusInProgress || issue.Assignee == nil || *issue.Assignee != agent {
+		t.Errorf("expected issue-2 claimed by %s, got %+v", agent, issue)
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimIssueByID_Errors(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	owner := "owner-agent"
+	insertTestIssue(t, dbPath, "claimed", "Claimed", "in_progress", 1, &owner)
+	insertTestIssue(t, dbPath, "closed", "Closed", "closed", 1, nil)
+	insertTestIssue(t, dbPath, "blocked", "Blocked", "open", 1, nil)
+	blockIssue(t, dbPath, "blocked")
+	insertTestIssue(t, dbPath, "frontend", "Frontend", "open", 1, nil)
+	insertTestLabel(t, dbPath, "frontend", "frontend")
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+
+	tests := []struct {
+		name    string
+		issueID domain.IssueId
+		filters domain.ClaimFilters
+		code    domain.ClaimErrorCode
+	}{
+		{"missing", "missing", domain.NewClaimFilters(), domain.ErrCodeNotFound},
+		{"already claimed", "claimed", domain.NewClaimFilters(), domain.ErrCodeAlreadyClaimed},
+		{"closed", "closed", domain.NewClaimFilters(), domain.ErrCodeNotReady},
+		{"blocked", "blocked", domain.NewClaimFilters(), domain.ErrCodeNotReady},
+		{"filters do not match", "frontend", domain.ClaimFilters{ExcludeLabels: []string{"frontend"}}, domain.ErrCodeNotReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.ClaimIssueByID(context.Background(), agent, tt.issueID, tt.filters, domain.ClaimOptions{})
+			claimErr, ok := err.(*domain.ClaimFailed)
+			if !ok || claimErr.ErrorCode != tt.code {
+				t.Errorf("expected %s error, got %v", tt.code, err)
+			}
+
+			_, err = repo.FindIssueByID(context.Background(), tt.issueID, tt.filters)
+			claimErr, ok = err.(*domain.ClaimFailed)
+			if !ok || claimErr.ErrorCode != tt.code {
+				t.Errorf("dry-run: expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+
+	// Nothing was claimed along the way
+	other, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == nil || other.ID != "frontend" {
+		t.Errorf("expected frontend to still be ready, got %+v", other)
+	}
+}
+
 // TestHighlanderRace tests Scenario A: multiple agents racing for a single issue.
 // "There can be only one" - exactly one agent should claim the issue.
 func TestHighlanderRace(t *testing.T) {
@@ -577,7 +817,7 @@ func TestHighlanderRace(t *testing.T) {
 			// Wait for start signal
 			<-start
 
-			issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters())
+			issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{})
 			if err != nil {
 				errors <- err
 				return
@@ -653,7 +893,7 @@ func TestHungryHipposRace(t *testing.T) {
 
 			// Keep claiming until no more issues
 			for {
-				issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters())
+				issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{})
 				if err != nil {
 					t.Errorf("agent %s got error: %v", agentName, err)
 					return
@@ -696,9 +936,9 @@ func TestSQLiteIssueRepository_FindOneReadyIssue_Filters(t *testing.T) {
 	defer cleanup()
 
 	assignee := "other-agent"
-	insertTestIssue(t, dbPath, "issue-1", "Assigned Issue", "open", 1, &assignee)
-	insertTestIssue(t, dbPath, "issue-2", "Low Priority", "open", 0, nil)
-	insertTestIssue(t, dbPath, "issue-3", "High Priority", "open", 2, nil)
+	insertTestIssue(t, dbPath, "issue-1", "Assigned Issue", "open", 2, &assignee)
+	insertTestIssue(t, dbPath, "issue-2", "Low Priority", "open", 3, nil)
+	insertTestIssue(t, dbPath, "issue-3", "High Priority", "open", 1, nil)
 
 	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
 	if err != nil {
@@ -719,9 +959,9 @@ func TestSQLiteIssueRepository_FindOneReadyIssue_Filters(t *testing.T) {
 		t.Error("should not find assigned issue")
 	}
 
-	// Test MinPriority filter
-	minPriority := domain.PriorityHigh
-	filters = domain.ClaimFilters{MinPriority: &minPriority}
+	// Test MaxPriority filter
+	maxPriority := domain.PriorityHigh
+	filters = domain.ClaimFilters{MaxPriority: &maxPriority}
 	issue, err = repo.FindOneReadyIssue(context.Background(), filters)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -820,7 +1060,7 @@ func TestSQLiteIssueRepository_AllFilters(t *testing.T) {
 	}
 
 	agent, _ := domain.NewAgentName("test-agent")
-	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters)
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), agent, filters, domain.ClaimOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -863,12 +1103,12 @@ func TestSQLiteIssueRepository_FindWithExcludeLabels(t *testing.T) {
 	}
 }
 
-func TestSQLiteIssueRepository_FindWithMinPriority(t *testing.T) {
+func TestSQLiteIssueRepository_FindWithMaxPriority(t *testing.T) {
 	dbPath, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	insertTestIssue(t, dbPath, "issue-1", "Low Priority", "open", 0, nil)
-	insertTestIssue(t, dbPath, "issue-2", "High Priority", "open", 2, nil)
+	insertTestIssue(t, dbPath, "issue-1", "Low Priority", "open", 3, nil)
+	insertTestIssue(t, dbPath, "issue-2", "High Priority", "open", 1, nil)
 
 	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
 	if err != nil {
@@ -876,9 +1116,9 @@ func TestSQLiteIssueRepository_FindWithMinPriority(t *testing.T) {
 	}
 	defer repo.Close()
 
-	minPriority := domain.PriorityHigh
+	maxPriority := domain.PriorityHigh
 	filters := domain.ClaimFilters{
-		MinPriority: &minPriority,
+		MaxPriority: &maxPriority,
 	}
 
 	issue, err := repo.FindOneReadyIssue(context.Background(), filters)
@@ -923,6 +1163,350 @@ func TestSQLiteIssueRepository_FindWithOnlyUnassigned(t *testing.T) {
 	}
 }
 
+func TestSQLiteIssueRepository_FindIssuesByAssignee(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	mine := "agent-a"
+	theirs := "agent-b"
+	insertTestIssue(t, dbPath, "mine-low", "Mine low", "in_progress", 3, &mine)
+	insertTestIssue(t, dbPath, "mine-high", "Mine high", "in_progress", 0, &mine)
+	insertTestIssue(t, dbPath, "mine-closed", "Mine closed", "closed", 0, &mine)
+	insertTestIssue(t, dbPath, "theirs", "Theirs", "in_progress", 0, &theirs)
+	insertTestIssue(t, dbPath, "open", "Open", "open", 0, nil)
+	insertTestLabel(t, dbPath, "mine-high", "backend")
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	issues, err := repo.FindIssuesByAssignee(context.Background(), "agent-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 || issues[0].ID != "mine-high" || issues[1].ID != "mine-low" {
+		t.Fatalf("expected [mine-high mine-low], got %+v", issues)
+	}
+	if !issues[0].Labels.Contains("backend") {
+		t.Errorf("expected labels to be loaded, got %v", issues[0].Labels)
+	}
+}
+
+func TestSQLiteIssueRepository_ReleaseIssue(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	addBeadsAuditTables(t, dbPath)
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	if _, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	issue, err := repo.ReleaseIssue(context.Background(), agent, "issue-1", "needs design input")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Status != domain.StatusOpen {
+		t.Errorf("expected status 'open', got '%s'", issue.Status)
+	}
+	if issue.Assignee != nil {
+		t.Errorf("expected assignee to be cleared, got %s", *issue.Assignee)
+	}
+
+	// The issue is back in the ready pool
+	found, err := repo.FindOneReadyIssue(context.Background(), domain.NewClaimFilters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.ID != "issue-1" {
+		t.Error("expected released issue to be ready again")
+	}
+
+	events := fetchTestEvents(t, dbPath, "issue-1")
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events (claim + release), got %d", len(events))
+	}
+	release := events[2]
+	if release.oldValue.String != "in_progress" || release.newValue.String != "open" ||
+		release.comment.String != "needs design input" {
+		t.Errorf("unexpected release event: %+v", release)
+	}
+
+	comments := fetchTestComments(t, dbPath, "issue-1")
+	if len(comments) != 1 || comments[0] != "Released by test-agent: needs design input" {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+}
+
+func TestSQLiteIssueRepository_ReleaseIssue_NotOwner(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	owner := "owner-agent"
+	insertTestIssue(t, dbPath, "issue-1", "Claimed Issue", "in_progress", 1, &owner)
+	insertTestIssue(t, dbPath, "issue-2", "Open Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	tests := []struct {
+		issueID domain.IssueId
+		code    domain.ClaimErrorCode
+	}{
+		{"issue-1", domain.ErrCodeNotOwner},
+		{"issue-2", domain.ErrCodeNotOwner},
+		{"missing", domain.ErrCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.issueID.String(), func(t *testing.T) {
+			_, err := repo.ReleaseIssue(context.Background(), agent, tt.issueID, "")
+			claimErr, ok := err.(*domain.ClaimFailed)
+			if !ok {
+				t.Fatalf("expected ClaimFailed error, got %v", err)
+			}
+			if claimErr.ErrorCode != tt.code {
+				t.Errorf("expected %s, got %s", tt.code, claimErr.ErrorCode)
+			}
+		})
+	}
+
+	// The other agent's claim is untouched
+	issue, err := repo.ReleaseIssue(context.Background(), domain.AgentName(owner), "issue-1", "")
+	if err != nil {
+		t.Fatalf("owner release failed: %v", err)
+	}
+	if issue.Status != domain.StatusOpen {
+		t.Errorf("expected status 'open', got '%s'", issue.Status)
+	}
+}
+
+func TestSQLiteIssueRepository_CloseIssue(t *testing.T) {
+	// The Beads schema requires closed_at exactly when status is closed
+	dbPath, cleanup := setupBeadsSchemaDB(t)
+	defer cleanup()
+	execTestSQL(t, dbPath, `ALTER TABLE issues ADD COLUMN close_reason TEXT DEFAULT ''`)
+	execTestSQL(t, dbPath, `INSERT INTO issues (id, title, status) VALUES ('issue-1', 'Test Issue', 'open')`)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	agent, _ := domain.NewAgentName("test-agent")
+	if _, err := repo.ClaimOneReadyIssue(context.Background(), agent, domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+
+	issue, err := repo.CloseIssue(context.Background(), agent, "issue-1", "shipped")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Status != domain.StatusClosed || issue.ClosedAt == nil {
+		t.Errorf("expected closed issue with closed_at, got %+v", issue)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var status, reason string
+	var closedAt sql.NullString
+	if err := db.QueryRow(`SELECT status, closed_at, close_reason FROM issues WHERE id = 'issue-1'`).Scan(&status, &closedAt, &reason); err != nil {
+		t.Fatal(err)
+	}
+	if status != "closed" || !closedAt.Valid || reason != "shipped" {
+		t.Errorf("unexpected row: status=%s closed_at=%v close_reason=%q", status, closedAt, reason)
+	}
+
+	events := fetchTestEvents(t, dbPath, "issue-1")
+	last := events[len(events)-1]
+	if last.eventType != "closed" || last.actor != "test-agent" || last.comment.String != "shipped" {
+		t.Errorf("unexpected close event: %+v", last)
+	}
+	if n := countLeases(t, dbPath); n != 0 {
+		t.Errorf("expected the lease to be dropped, got %d", n)
+	}
+
+	// Closing again fails: the issue is no longer in progress
+	_, err = repo.CloseIssue(context.Background(), agent, "issue-1", "")
+	if claimErr, ok := err.(*domain.ClaimFailed); !ok || claimErr.ErrorCode != domain.ErrCodeAlreadyClosed {
+		t.Errorf("expected ALREADY_CLOSED error, got %v", err)
+	}
+}
+
+func TestSQLiteIssueRepository_CloseIssue_Errors(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	owner := "owner-agent"
+	insertTestIssue(t, dbPath, "claimed", "Claimed Issue", "in_progress", 1, &owner)
+	insertTestIssue(t, dbPath, "open", "Open Issue", "open", 1, nil)
+	insertTestIssue(t, dbPath, "closed", "Closed Issue", "closed", 1, &owner)
+	insertTestIssue(t, dbPath, "orphan", "Orphaned Issue", "in_progress", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	tests := []struct {
+		issueID domain.IssueId
+		code    domain.ClaimErrorCode
+	}{
+		{"claimed", domain.ErrCodeNotOwner},
+		{"orphan", domain.ErrCodeNotOwner},
+		{"open", domain.ErrCodeNotClaimed},
+		{"closed", domain.ErrCodeAlreadyClosed},
+		{"missing", domain.ErrCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.issueID.String(), func(t *testing.T) {
+			_, err := repo.CloseIssue(context.Background(), "test-agent", tt.issueID, "")
+			claimErr, ok := err.(*domain.ClaimFailed)
+			if !ok || claimErr.ErrorCode != tt.code {
+				t.Errorf("expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+
+	// Schemas without closed_at still close
+	issue, err := repo.CloseIssue(context.Background(), domain.AgentName(owner), "claimed", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Status != domain.StatusClosed {
+		t.Errorf("expected status 'closed', got '%s'", issue.Status)
+	}
+}
+
+func TestSQLiteIssueRepository_ClaimWithWIPLimit(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	agent := "test-agent"
+	insertTestIssue(t, dbPath, "held", "Held Issue", "in_progress", 1, &agent)
+	insertTestIssue(t, dbPath, "issue-1", "First", "open", 1, nil)
+	insertTestIssue(t, dbPath, "issue-2", "Second", "open", 2, nil)
+	insertTestIssue(t, dbPath, "issue-3", "Third", "open", 3, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	opts := domain.ClaimOptions{MaxInProgress: 2}
+
+	// A batch claim is cut down to the agent's remaining capacity
+	issues, err := repo.ClaimReadyIssues(context.Background(), "test-agent", domain.NewClaimFilters(), 3, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != "issue-1" {
+		t.Fatalf("expected only issue-1 to be claimed, got %v", issues)
+	}
+
+	_, err = repo.ClaimOneReadyIssue(context.Background(), "test-agent", domain.NewClaimFilters(), opts)
+	claimErr, ok := err.(*domain.ClaimFailed)
+	if !ok || claimErr.ErrorCode != domain.ErrCodeWIPLimitReached {
+		t.Fatalf("expected WIP_LIMIT_REACHED error, got %v", err)
+	}
+	if len(claimErr.Issues) != 2 || claimErr.Issues[0].ID != "held" || claimErr.Issues[1].ID != "issue-1" {
+		t.Errorf("expected the agent's issues in the error, got %v", claimErr.Issues)
+	}
+
+	_, err = repo.ClaimIssueByID(context.Background(), "test-agent", "issue-2", domain.NewClaimFilters(), opts)
+	if claimErr, ok := err.(*domain.ClaimFailed); !ok || claimErr.ErrorCode != domain.ErrCodeWIPLimitReached {
+		t.Errorf("expected WIP_LIMIT_REACHED error, got %v", err)
+	}
+
+	// Other agents are not affected
+	if issue, err := repo.ClaimOneReadyIssue(context.Background(), "other-agent", domain.NewClaimFilters(), opts); err != nil || issue == nil {
+		t.Errorf("expected other agent to claim, got %v, %v", issue, err)
+	}
+}
+
+func TestSQLiteIssueRepository_TransferIssue(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+	addBeadsAuditTables(t, dbPath)
+
+	insertTestIssue(t, dbPath, "issue-1", "Test Issue", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	from, _ := domain.NewAgentName("planner")
+	to, _ := domain.NewAgentName("specialist")
+	if _, err := repo.ClaimOneReadyIssue(context.Background(), from, domain.NewClaimFilters(), domain.ClaimOptions{Lease: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Heartbeat(context.Background(), from, "issue-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	issue, err := repo.TransferIssue(context.Background(), from, to, "issue-1", "needs a DB expert")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Status != domain.StatusInProgress || issue.Assignee == nil || *issue.Assignee != to {
+		t.Errorf("expected in-progress issue assigned to specialist, got %+v", issue)
+	}
+	if issue.TransferredFrom == nil || *issue.TransferredFrom != from {
+		t.Errorf("expected TransferredFrom planner, got %v", issue.TransferredFrom)
+	}
+	if issue.LeaseExpiresAt == nil || time.Until(*issue.LeaseExpiresAt) < 59*time.Minute {
+		t.Errorf("expected the lease to restart for the new owner, got %v", issue.LeaseExpiresAt)
+	}
+
+	events := fetchTestEvents(t, dbPath, "issue-1")
+	last := events[len(events)-1]
+	if last.eventType != "assignee_changed" || last.oldValue.String != "planner" || last.newValue.String != "specialist" {
+		t.Errorf("unexpected transfer event: %+v", last)
+	}
+
+	// The old owner has lost the issue; the new one holds it
+	if _, err := repo.Heartbeat(context.Background(), from, "issue-1"); err == nil {
+		t.Error("expected heartbeat from the previous owner to fail")
+	}
+	if _, err := repo.Heartbeat(context.Background(), to, "issue-1"); err != nil {
+		t.Errorf("expected heartbeat from the new owner to succeed, got %v", err)
+	}
+
+	// The issue never became claimable in between
+	if claimed, err := repo.ClaimOneReadyIssue(context.Background(), "other-agent", domain.NewClaimFilters(), domain.ClaimOptions{}); err != nil || claimed != nil {
+		t.Errorf("expected nothing to claim, got %v, %v", claimed, err)
+	}
+
+	_, err = repo.TransferIssue(context.Background(), from, to, "issue-1", "")
+	if claimErr, ok := err.(*domain.ClaimFailed); !ok || claimErr.ErrorCode != domain.ErrCodeNotOwner {
+		t.Errorf("expected NOT_OWNER error, got %v", err)
+	}
+}
+
 func setupTestDBWithMetadata(t *testing.T, version string) (string, func()) {
 	t.Helper()
 
@@ -1132,3 +1716,149 @@ func TestParseVersion(t *testing.T) {
 		})
 	}
 }
+
+// TestLabelExprCondition_Parity checks that the SQL translation of label
+// expressions selects exactly the issues Issue.CanBeClaimed accepts.
+func TestLabelExprCondition_Parity(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	fixtures := map[string]domain.LabelSet{
+		"none":     nil,
+		"fe":       {"frontend"},
+		"docs":     {"docs"},
+		"fe-docs":  {"frontend", "docs"},
+		"docs-ext": {"docs", "blocked-external"},
+		"fe-ext":   {"frontend", "blocked-external"},
+		"spaced":   {"needs review"},
+		"api":      {"component:api", "backend"},
+	}
+	var issues []*domain.Issue
+	for id, labels := range fixtures {
+		insertTestIssue(t, dbPath, id, id, "open", 1, nil)
+		for _, label := range labels {
+			insertTestLabel(t, dbPath, id, label)
+		}
+		issues = append(issues, &domain.Issue{ID: domain.IssueId(id), Status: domain.StatusOpen, Labels: labels})
+	}
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	expressions := []string{
+		"label:frontend",
+		"not label:frontend",
+		"label:frontend or label:docs",
+		"label:frontend and label:docs",
+		"label:frontend or label:docs and not label:blocked-external",
+		"(label:frontend or label:docs) and not label:blocked-external",
+		"not (label:frontend or label:docs)",
+		"not not label:docs",
+		`label:"needs review" or label:component:api`,
+		"label:missing",
+		"not label:missing",
+	}
+
+	for _, s := range expressions {
+		t.Run(s, func(t *testing.T) {
+			expr, err := domain.ParseLabelExpr(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filters := domain.NewClaimFilters()
+			filters.Where = expr
+
+			want := map[domain.IssueId]bool{}
+			for _, issue := range issues {
+				if issue.CanBeClaimed(filters) {
+					want[issue.ID] = true
+				}
+			}
+
+			ready, err := repo.FindReadyIssues(context.Background(), filters, len(fixtures))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := map[domain.IssueId]bool{}
+			for _, issue := range ready {
+				got[issue.ID] = true
+			}
+
+			if len(got) != len(want) {
+				t.Errorf("SQL selected %v, CanBeClaimed accepts %v", got, want)
+			}
+			for id := range want {
+				if !got[id] {
+					t.Errorf("SQL selected %v, CanBeClaimed accepts %v", got, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteIssueRepository_IssueTypes(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Same priority, created oldest first in this order
+	for _, fixture := range []struct{ id, issueType string }{
+		{"chore-1", "chore"},
+		{"epic-1", "epic"},
+		{"task-1", "task"},
+		{"custom-1", "spike"},
+		{"untyped-1", ""},
+		{"feature-1", "feature"},
+		{"bug-1", "bug"},
+	} {
+		insertTestIssue(t, dbPath, fixture.id, fixture.id, "open", 2, nil)
+		if fixture.issueType != "" {
+			execTestSQL(t, dbPath, `UPDATE issues SET issue_type = ? WHERE id = ?`, fixture.issueType, fixture.id)
+		}
+	}
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ids := func(filters domain.ClaimFilters) []domain.IssueId {
+		t.Helper()
+		issues, err := repo.FindReadyIssues(context.Background(), filters, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []domain.IssueId
+		for _, issue := range issues {
+			ids = append(ids, issue.ID)
+		}
+		return ids
+	}
+	expect := func(name string, got []domain.IssueId, want ...domain.IssueId) {
+		t.Helper()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+
+	// Unlisted and missing types keep their created_at order at the end
+	expect("default order", ids(domain.NewClaimFilters()),
+		"bug-1", "feature-1", "task-1", "epic-1", "chore-1", "custom-1", "untyped-1")
+
+	filters := domain.NewClaimFilters()
+	filters.TypeOrder = []string{"chore", "spike"}
+	expect("configured order", ids(filters),
+		"chore-1", "custom-1", "epic-1", "task-1", "untyped-1", "feature-1", "bug-1")
+
+	filters = domain.NewClaimFilters()
+	filters.IncludeTypes = []string{"epic", "feature"}
+	expect("include types", ids(filters), "feature-1", "epic-1")
+
+	filters = domain.NewClaimFilters()
+	filters.ExcludeTypes = []string{"chore", "epic", "spike"}
+	expect("exclude types", ids(filters), "bug-1", "feature-1", "task-1", "untyped-1")
+}
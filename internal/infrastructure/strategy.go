@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// dependentsCTE pairs every candidate issue with each unfinished issue waiting
+// on it: its 'blocks' dependents, their dependents, and, since blockage flows
+// down 'parent-child' edges, their descendants. The walk starts from the
+// candidates CTE alone, and UNION drops repeated pairs, so a dependency cycle
+// ends the recursion. scores counts the pairs for each candidate.
+const dependentsCTE = `
+	dependents(root, id) AS (
+		SELECT d.depends_on_id, d.issue_id
+		FROM dependencies d
+		JOIN issues di ON di.id = d.issue_id
+		WHERE d.depends_on_id IN (SELECT id FROM candidates)
+		AND d.type = 'blocks'
+		AND di.status IN ('open', 'in_progress', 'blocked')
+		UNION
+		SELECT dp.root, d.issue_id
+		FROM dependents dp
+		JOIN dependencies d ON d.depends_on_id = dp.id
+		JOIN issues di ON di.id = d.issue_id
+		WHERE d.type IN ('blocks', 'parent-child')
+		AND di.status IN ('open', 'in_progress', 'blocked')
+	),
+	scores(id, unblocks) AS (
+		SELECT root, COUNT(*) FROM dependents WHERE id != root GROUP BY root
+	)`
+
+// unblocksScore is the critical-path score of the issue aliased i, given
+// scores from dependentsCTE joined as s: how many issues wait on it.
+const unblocksScore = `COALESCE(s.unblocks, 0)`
+
+// usesCriticalPath reports whether filters order by critical path and the
+// database has the dependency graph to compute it. Without one every score is
+// zero, so the ordering is left as it is.
+func usesCriticalPath(ctx context.Context, q queryRower, filters domain.ClaimFilters) (bool, error) {
+	if filters.Strategy != domain.StrategyCriticalPath {
+		return false, nil
+	}
+	return hasTable(ctx, q, "dependencies")
+}
+
+// scoreColumn returns the score readySelect adds to its columns: unblocksScore
+// under the critical-path strategy, zero when there is no dependency graph to
+// walk, and NULL under any other strategy.
+func scoreColumn(filters domain.ClaimFilters, criticalPath bool) string {
+	switch {
+	case criticalPath:
+		return unblocksScore
+	case filters.Strategy == domain.StrategyCriticalPath:
+		return "0"
+	default:
+		return "NULL"
+	}
+}
+
+// unblocks converts a scanned score column to Issue.Unblocks.
+func unblocks(score sql.NullInt64) *int {
+	if !score.Valid {
+		return nil
+	}
+	n := int(score.Int64)
+	return &n
+}
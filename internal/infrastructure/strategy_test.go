@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ccheney/bd-claim/internal/domain"
+)
+
+// setupCriticalPathFixture builds a graph where a low-priority blocker holds
+// up most of the work:
+//
+//	blocker --blocks--> a --blocks--> c
+//	        --blocks--> b --blocks--> epic --parent-child--> child
+//	        --blocks--> gone (closed)
+//	small   --blocks--> d --blocks--> small (a cycle)
+//	leaf, the most urgent, holds up nothing
+func setupCriticalPathFixture(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dbPath, cleanup := setupTestDB(t)
+	addDependenciesTable(t, dbPath)
+
+	insertTestIssue(t, dbPath, "leaf", "Leaf", "open", 1, nil)
+	insertTestIssue(t, dbPath, "blocker", "Blocker", "open", 3, nil)
+	for _, id := range []string{"a", "b", "c", "epic", "child", "small", "d"} {
+		insertTestIssue(t, dbPath, id, id, "open", 2, nil)
+	}
+	insertTestIssue(t, dbPath, "gone", "Gone", "closed", 2, nil)
+
+	insertTestDependency(t, dbPath, "a", "blocker", "blocks")
+	insertTestDependency(t, dbPath, "b", "blocker", "blocks")
+	insertTestDependency(t, dbPath, "gone", "blocker", "blocks")
+	insertTestDependency(t, dbPath, "c", "a", "blocks")
+	insertTestDependency(t, dbPath, "epic", "b", "blocks")
+	insertTestDependency(t, dbPath, "child", "epic", "parent-child")
+	insertTestDependency(t, dbPath, "d", "small", "blocks")
+	insertTestDependency(t, dbPath, "small", "d", "blocks")
+
+	return dbPath, cleanup
+}
+
+func TestCriticalPathStrategy(t *testing.T) {
+	dbPath, cleanup := setupCriticalPathFixture(t)
+	defer cleanup()
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	// The cache is empty, so every open issue is ready and only the order differs
+	filters := domain.NewClaimFilters()
+	issues, err := repo.FindReadyIssues(context.Background(), filters, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issues[0].ID != "leaf" || issues[0].Unblocks != nil {
+		t.Errorf("expected leaf first and unscored by priority, got %s (%v)", issues[0].ID, issues[0].Unblocks)
+	}
+
+	filters.Strategy = domain.StrategyCriticalPath
+	issues, err = repo.FindReadyIssues(context.Background(), filters, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Closed dependents do not count, and the cycle counts d once for small.
+	// An open epic does not block its children, so only blockage inherited
+	// from above reaches child.
+	wantScores := map[domain.IssueId]int{
+		"blocker": 5, "b": 2, "a": 1, "small": 1, "d": 1, "leaf": 0, "c": 0, "epic": 0, "child": 0,
+	}
+	if len(issues) != len(wantScores) {
+		t.Fatalf("expected %d issues, got %d", len(wantScores), len(issues))
+	}
+	for i, issue := range issues {
+		if issue.Unblocks == nil || *issue.Unblocks != wantScores[issue.ID] {
+			t.Errorf("%s: expected score %d, got %v", issue.ID, wantScores[issue.ID], issue.Unblocks)
+		}
+		if i > 0 && *issue.Unblocks > *issues[i-1].Unblocks {
+			t.Errorf("%s (score %d) ordered after %s (score %d)", issue.ID, *issue.Unblocks, issues[i-1].ID, *issues[i-1].Unblocks)
+		}
+	}
+
+	// Equal scores fall back to priority
+	if issues[len(issues)-4].ID != "leaf" {
+		t.Errorf("expected leaf to lead the unscored issues, got %s", issues[len(issues)-4].ID)
+	}
+
+	issue, err := repo.ClaimOneReadyIssue(context.Background(), "agent-a", filters, domain.ClaimOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue == nil || issue.ID != "blocker" || issue.Unblocks == nil || *issue.Unblocks != 5 {
+		t.Errorf("expected to claim blocker with score 5, got %+v", issue)
+	}
+
+	// The walk starts from b alone but still reaches dependents outside the selection
+	issue, err = repo.FindIssueByID(context.Background(), "b", filters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.Unblocks == nil || *issue.Unblocks != 2 {
+		t.Errorf("expected b to score 2 on its own, got %v", issue.Unblocks)
+	}
+}
+
+func TestCriticalPathStrategy_NoDependencies(t *testing.T) {
+	dbPath, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertTestIssue(t, dbPath, "older", "Older", "open", 2, nil)
+	insertTestIssue(t, dbPath, "urgent", "Urgent", "open", 1, nil)
+
+	repo, err := NewSQLiteIssueRepository(dbPath, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	filters := domain.NewClaimFilters()
+	filters.Strategy = domain.StrategyCriticalPath
+	issues, err := repo.FindReadyIssues(context.Background(), filters, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 || issues[0].ID != "urgent" {
+		t.Fatalf("expected priority order without a dependency graph, got %v", issues)
+	}
+	if issues[0].Unblocks == nil || *issues[0].Unblocks != 0 {
+		t.Errorf("expected a zero score, got %v", issues[0].Unblocks)
+	}
+}